@@ -0,0 +1,70 @@
+package main
+
+import (
+	"time"
+)
+
+const (
+	latencyAlpha     = 0.2
+	delayBackoff     = 2.0
+	delaySlowdown    = 1.5
+	delayRecovery    = 0.9
+	minBackoffStep   = 100 * time.Millisecond
+	slowLatencyRatio = 2.0
+)
+
+// adaptiveDelay tracks one worker's response times and adjusts the pause
+// between its requests: backing off on 429/503 or climbing latency and
+// recovering toward -min-delay while responses stay fast.
+type adaptiveDelay struct {
+	delay    time.Duration
+	avg      float64
+	baseline float64
+	samples  int
+}
+
+func newAdaptiveDelay() *adaptiveDelay {
+	return &adaptiveDelay{delay: *minDelay}
+}
+
+func (a *adaptiveDelay) Wait() {
+	if a.delay > 0 {
+		time.Sleep(a.delay)
+	}
+}
+
+func (a *adaptiveDelay) Observe(status int, elapsed time.Duration) {
+	ms := float64(elapsed) / float64(time.Millisecond)
+	if a.samples == 0 {
+		a.avg = ms
+	} else {
+		a.avg = latencyAlpha*ms + (1-latencyAlpha)*a.avg
+	}
+	a.samples++
+	// The fastest average seen approximates the server's healthy latency.
+	if a.baseline == 0 || a.avg < a.baseline {
+		a.baseline = a.avg
+	}
+
+	switch {
+	case status == 429 || status == 503:
+		a.delay = time.Duration(float64(a.delay) * delayBackoff)
+		if a.delay < minBackoffStep {
+			a.delay = minBackoffStep
+		}
+	case a.avg > a.baseline*slowLatencyRatio:
+		a.delay = time.Duration(float64(a.delay) * delaySlowdown)
+		if a.delay < minBackoffStep {
+			a.delay = minBackoffStep
+		}
+	default:
+		a.delay = time.Duration(float64(a.delay) * delayRecovery)
+	}
+
+	if a.delay > *maxDelay {
+		a.delay = *maxDelay
+	}
+	if a.delay < *minDelay {
+		a.delay = *minDelay
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	// maxAPIBody is the largest JSON body -api-mode parses.
+	maxAPIBody = 1 << 20
+	// maxAPIKeys is how many top-level keys are listed in the title.
+	maxAPIKeys = 8
+)
+
+// apiTitle describes a JSON or gRPC-web response for -api-mode: the
+// top-level keys of an object, or the length and element keys of an array.
+func apiTitle(contentType string, body []byte) (string, bool) {
+	ct := strings.ToLower(contentType)
+	if strings.HasPrefix(ct, "application/grpc-web") || strings.HasPrefix(ct, "application/grpc") {
+		return "gRPC endpoint (" + strings.TrimSpace(strings.Split(ct, ";")[0]) + ")", true
+	}
+	trimmed := bytes.TrimSpace(body)
+	if contentKind(contentType) != "json" && !(len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')) {
+		return "", false
+	}
+	if len(body) > maxAPIBody {
+		return fmt.Sprintf("JSON (%d bytes, not parsed)", len(body)), true
+	}
+
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		var items []json.RawMessage
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return "", false
+		}
+		if len(items) > 0 {
+			if keys, ok := jsonKeys(items[0]); ok {
+				return fmt.Sprintf("[%d × {%s}]", len(items), joinKeys(keys)), true
+			}
+		}
+		return fmt.Sprintf("[%d items]", len(items)), true
+	}
+	keys, ok := jsonKeys(trimmed)
+	if !ok {
+		return "", false
+	}
+	return "{" + joinKeys(keys) + "}", true
+}
+
+// jsonKeys returns the top-level keys of a JSON object in document order.
+func jsonKeys(data []byte) ([]string, bool) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, false
+	}
+	keys := []string{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, false
+		}
+		keys = append(keys, tok.(string))
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return nil, false
+		}
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, false
+	}
+	return keys, true
+}
+
+func joinKeys(keys []string) string {
+	if len(keys) > maxAPIKeys {
+		return strings.Join(keys[:maxAPIKeys], ", ") + fmt.Sprintf(", +%d", len(keys)-maxAPIKeys)
+	}
+	return strings.Join(keys, ", ")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// autoFilterSamples is how many responses a host must have sent before a
+// dominant status can be hidden.
+const autoFilterSamples = 50
+
+type hostStatuses struct {
+	mu     sync.Mutex
+	counts map[int]int
+	total  int
+	hidden map[int]bool
+}
+
+var statusCounts sync.Map // host -> *hostStatuses
+
+// observeStatus counts status for host under -auto-filter-status and reports
+// whether it should be hidden: once a status makes up at least
+// -auto-filter-threshold of a host's responses, it is hidden from then on.
+func observeStatus(host string, status int) bool {
+	v, _ := statusCounts.LoadOrStore(host, &hostStatuses{counts: make(map[int]int), hidden: make(map[int]bool)})
+	h := v.(*hostStatuses)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.counts[status]++
+	h.total++
+	if h.hidden[status] {
+		return true
+	}
+	if h.total < autoFilterSamples || matchStatus[status] {
+		return false
+	}
+	share := float64(h.counts[status]) / float64(h.total)
+	if share < *autoFilterThreshold {
+		return false
+	}
+	h.hidden[status] = true
+	emitLine(fmt.Sprintf("%s %s returned %d for %.0f%% of %d responses, hiding it",
+		yellow("[auto-filter]"), host, status, share*100, h.total))
+	logger.Info("auto-filter hiding status", "host", host, "status", status, "share", share, "responses", h.total)
+	return true
+}
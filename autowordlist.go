@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+//go:embed wordlists/*.txt
+var bundledWordlists embed.FS
+
+// stackWordlists maps detected technologies to the bundled list scanned for
+// them, checked in order so a more specific match wins.
+var stackWordlists = []struct {
+	list string
+	tech []string
+}{
+	{"iis", []string{"IIS", "ASP.NET"}},
+	{"php", []string{"PHP", "WordPress", "Drupal", "Joomla", "Laravel"}},
+	{"java", []string{"Java", "Tomcat", "Spring"}},
+}
+
+// scanGroup is a set of targets scanned with the same jobs.
+type scanGroup struct {
+	dirs    []job
+	targets []Target
+}
+
+// autoWordlistGroups fingerprints the root of every target host and groups
+// the targets by the bundled list for their stack. Targets whose stack is
+// not recognised get the -w jobs, or are skipped when there are none.
+func autoWordlistGroups(urls []Target, dirs []job) []scanGroup {
+	if len(wordlists) == 0 {
+		dirs = nil
+	}
+	client := newClient()
+
+	var hosts []string
+	first := make(map[string]Target)
+	for _, t := range urls {
+		host := hostOf(t.URL)
+		if _, ok := first[host]; !ok {
+			hosts = append(hosts, host)
+			first[host] = t
+		}
+	}
+
+	chosen := make(map[string]string, len(hosts))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, *threads)
+	for _, host := range hosts {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(host string, t Target) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			list := detectWordlist(client, host, t)
+			mu.Lock()
+			chosen[host] = list
+			mu.Unlock()
+		}(host, first[host])
+	}
+	wg.Wait()
+
+	var groups []scanGroup
+	index := make(map[string]int)
+	for _, host := range hosts {
+		list := chosen[host]
+		switch {
+		case list != "":
+			fmt.Println(blue(fmt.Sprintf("[auto-wordlist] %s: %s", host, list)))
+		case len(dirs) > 0:
+			fmt.Println(blue(fmt.Sprintf("[auto-wordlist] %s: stack not recognised, using -w", host)))
+		default:
+			fmt.Println(yellow(fmt.Sprintf("[auto-wordlist] %s: stack not recognised and no -w given, skipping", host)))
+		}
+	}
+	for _, t := range urls {
+		list := chosen[hostOf(t.URL)]
+		if list == "" && len(dirs) == 0 {
+			continue
+		}
+		i, ok := index[list]
+		if !ok {
+			g := scanGroup{dirs: dirs}
+			if list != "" {
+				g.dirs = bundledJobs(list)
+			}
+			i = len(groups)
+			index[list] = i
+			groups = append(groups, g)
+		}
+		groups[i].targets = append(groups[i].targets, t)
+	}
+	return groups
+}
+
+// detectWordlist requests the target's root and returns the bundled list
+// matching the stack found there, or "" when none does.
+func detectWordlist(client *fasthttp.Client, host string, t Target) string {
+	if !takeRequest() {
+		return ""
+	}
+	_, body, meta, err := getStatusCode(client, t, probeURL(t.URL, ""))
+	if err != nil {
+		return ""
+	}
+	found := make(map[string]bool)
+	for _, name := range fingerprint(host, meta.Headers, body) {
+		found[name] = true
+	}
+	for _, sw := range stackWordlists {
+		for _, name := range sw.tech {
+			if found[name] {
+				return sw.list
+			}
+		}
+	}
+	return ""
+}
+
+// bundledJobs turns a bundled list into jobs, with -x, -prefix and -suffix
+// applied as for a -w list.
+func bundledJobs(list string) []job {
+	data, err := bundledWordlists.ReadFile("wordlists/" + list + ".txt")
+	if err != nil {
+		return nil
+	}
+	var words []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if w := strings.TrimSpace(scanner.Text()); w != "" {
+			words = append(words, w)
+		}
+	}
+	var jobs []job
+	for _, w := range expandWords(addExtensions(words)) {
+		jobs = append(jobs, job{Values: []string{w}})
+	}
+	return jobs
+}
+
+// checkAutoWordlist disables -auto-wordlist for multi-keyword scans, where a
+// bundled word has no single place to go. Without -w the bundled words take
+// the FUZZ keyword.
+func checkAutoWordlist() bool {
+	switch {
+	case len(keywords) > 1:
+		fmt.Println(yellow("Warning: -auto-wordlist only works with a single wordlist keyword, ignoring it"))
+		return false
+	case len(keywords) == 0:
+		keywords = []string{defaultKeyword}
+		keywordOrder = []int{0}
+	}
+	return true
+}
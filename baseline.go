@@ -0,0 +1,114 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type baselineEntry struct {
+	Status       int
+	Length       int
+	ETag         string
+	LastModified string
+}
+
+// baseline maps URLs to their result in a previous scan's -db file, or in
+// the previous pass with -interval. It is only replaced between passes.
+var baseline map[string]baselineEntry
+
+func loadBaseline(path string) (map[string]baselineEntry, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	columns, err := dbColumns(db)
+	if err != nil {
+		return nil, err
+	}
+	query := "SELECT url, status, length, '', '' FROM results ORDER BY id"
+	if columns["etag"] && columns["last_modified"] {
+		query = "SELECT url, status, length, COALESCE(etag, ''), COALESCE(last_modified, '') FROM results ORDER BY id"
+	}
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make(map[string]baselineEntry)
+	for rows.Next() {
+		var url string
+		var e baselineEntry
+		if err := rows.Scan(&url, &e.Status, &e.Length, &e.ETag, &e.LastModified); err != nil {
+			return nil, err
+		}
+		entries[url] = e
+	}
+	return entries, rows.Err()
+}
+
+// diffAgainstBaseline marks r as new or changed and reports whether it
+// differs from the baseline at all.
+func diffAgainstBaseline(r *Result) bool {
+	prev, ok := baseline[r.URL]
+	switch {
+	case !ok:
+		r.Diff = "new"
+	case prev.Status != r.Status || contentChanged(prev, *r):
+		r.Diff = "changed"
+		r.PrevStatus = prev.Status
+		r.PrevLength = prev.Length
+	default:
+		return false
+	}
+	return true
+}
+
+// contentChanged prefers the server's validators over the body length when
+// both scans captured them, so dynamic pages of varying size with a stable
+// ETag are not reported as changes.
+func contentChanged(prev baselineEntry, r Result) bool {
+	switch {
+	case prev.ETag != "" && r.ETag != "":
+		return prev.ETag != r.ETag
+	case prev.LastModified != "" && r.LastModified != "":
+		return prev.LastModified != r.LastModified
+	default:
+		return prev.Length != r.Length
+	}
+}
+
+// reportGone prints a baseline result that no longer matches.
+func reportGone(url string) {
+	prev, ok := baseline[url]
+	if !ok {
+		return
+	}
+	emitTerminal(Result{
+		URL:    url,
+		Status: prev.Status,
+		Length: prev.Length,
+		Diff:   "gone",
+	})
+}
+
+func diffMarker(r Result) string {
+	switch r.Diff {
+	case "new":
+		return green("[new]")
+	case "gone":
+		return red("[gone]")
+	case "changed":
+		marker := changed("[changed]")
+		if r.PrevLength != r.Length {
+			marker += changed(fmt.Sprintf(" size %d→%d", r.PrevLength, r.Length))
+		}
+		if prev := baseline[r.URL]; prev.ETag != "" && r.ETag != "" && prev.ETag != r.ETag {
+			marker += changed(" etag changed")
+		}
+		return marker
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// benchmarkRequests is how many paths each -benchmark round requests.
+const benchmarkRequests = 5000
+
+// benchmarkSeenKeys is how many URLs the seen-set comparison stores.
+const benchmarkSeenKeys = 1000000
+
+// benchmarkPage is the 404 body served, about the size of a typical error
+// page so response handling costs show up.
+var benchmarkPage = "<html><head><title>404 Not Found</title></head><body><h1>Not Found</h1>" +
+	strings.Repeat("<p>The requested URL was not found on this server.</p>", 36) + "</body></html>"
+
+// runBenchmark scans a local test server with each -benchmark-threads value
+// and prints the request rate achieved. Every path is a 404, so the full
+// worker pipeline runs without printing results.
+func runBenchmark() {
+	var counts []int
+	for _, s := range strings.Split(*benchmarkThreads, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(s))
+		if err != nil || n < 1 {
+			fmt.Println(red("Invalid -benchmark-threads value:"), s)
+			os.Exit(exitFatal)
+		}
+		counts = append(counts, n)
+	}
+
+	var served atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		served.Add(1)
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(benchmarkPage))
+	}))
+	defer server.Close()
+
+	urls := []Target{{URL: server.URL}}
+	dirs := make([]job, benchmarkRequests)
+	for i := range dirs {
+		dirs[i] = job{Values: []string{fmt.Sprintf("bench%d", i)}}
+	}
+
+	fmt.Printf("Benchmarking %d requests per round against %s\n", benchmarkRequests, server.URL)
+	fmt.Printf("%-10s %-12s %-10s %-12s %s\n", "Threads", "Requests", "Req/s", "Allocs/req", "Bytes/req")
+	for _, n := range counts {
+		served.Store(0)
+		var before, after runtime.MemStats
+		runtime.ReadMemStats(&before)
+		start := time.Now()
+		runScan(context.Background(), dirs, urls, n)
+		elapsed := time.Since(start)
+		runtime.ReadMemStats(&after)
+		// The allocations include the test server's, which runs in-process.
+		reqs := served.Load()
+		fmt.Printf("%-10d %-12d %-10.0f %-12.1f %.0f\n", n, reqs, float64(reqs)/elapsed.Seconds(),
+			float64(after.Mallocs-before.Mallocs)/float64(reqs), float64(after.TotalAlloc-before.TotalAlloc)/float64(reqs))
+	}
+	benchmarkSeenSets()
+}
+
+// benchmarkSeenSets compares the memory a map and a -bloom filter need for
+// benchmarkSeenKeys URLs, and the filter's false-positive rate on as many
+// URLs it never saw.
+func benchmarkSeenSets() {
+	keys := make([]string, benchmarkSeenKeys)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("https://example.com/some/path/word%d", i)
+	}
+
+	fmt.Printf("\nSeen set for %d URLs (-bloom-fp %g)\n", benchmarkSeenKeys, *bloomFP)
+	fmt.Printf("%-10s %-12s %s\n", "Set", "Memory", "False positives")
+
+	mapMem := measureHeap(func() any {
+		m := newMapSeenSet()
+		for _, k := range keys {
+			m.Add(k)
+		}
+		return m
+	})
+	fmt.Printf("%-10s %-12s %s\n", "map", fmt.Sprintf("%.1f MB", mapMem), "none")
+
+	var b *bloomSeenSet
+	bloomMem := measureHeap(func() any {
+		b = newBloomSeenSet(benchmarkSeenKeys, *bloomFP)
+		for _, k := range keys {
+			b.Add(k)
+		}
+		return b
+	})
+	falsePositives := 0
+	for i := range keys {
+		if b.contains(fmt.Sprintf("https://example.com/other/path/word%d", i)) {
+			falsePositives++
+		}
+	}
+	fmt.Printf("%-10s %-12s %.3f%%\n", "bloom", fmt.Sprintf("%.1f MB", bloomMem), float64(falsePositives)*100/float64(len(keys)))
+	// The keys must outlive both measurements or their release shows up as
+	// negative memory.
+	runtime.KeepAlive(keys)
+}
+
+// measureHeap returns how many MB of live heap the value fill builds takes.
+func measureHeap(fill func() any) float64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	v := fill()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(v)
+	return (float64(after.HeapAlloc) - float64(before.HeapAlloc)) / (1 << 20)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"hash/fnv"
+	"math"
+	"sync"
+)
+
+// bloomSeenSet is a seenSet in a fixed amount of memory, sized for capacity
+// keys at false-positive rate fp. The trade-off: a key never added is
+// occasionally reported as seen (about fp of the time once capacity keys
+// are in, rising beyond that), so a -seen-file URL may rarely be skipped as
+// already reported. A key that was added is never reported as new again.
+type bloomSeenSet struct {
+	mu     sync.Mutex
+	bits   []uint64
+	m      uint64
+	hashes uint64
+}
+
+func newBloomSeenSet(capacity int, fp float64) *bloomSeenSet {
+	if capacity < 1 {
+		capacity = 1
+	}
+	// The standard sizing: m = -n ln p / (ln 2)^2 bits and k = m/n ln 2
+	// hash functions.
+	m := uint64(math.Ceil(-float64(capacity) * math.Log(fp) / (math.Ln2 * math.Ln2)))
+	if m < 64 {
+		m = 64
+	}
+	k := uint64(math.Round(float64(m) / float64(capacity) * math.Ln2))
+	if k < 1 {
+		k = 1
+	}
+	return &bloomSeenSet{bits: make([]uint64, (m+63)/64), m: m, hashes: k}
+}
+
+// Add sets the key's bits and reports whether any was still clear.
+func (b *bloomSeenSet) Add(key string) bool {
+	h1, h2 := bloomHashes(key)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	added := false
+	for i := uint64(0); i < b.hashes; i++ {
+		word, bit := b.position(h1, h2, i)
+		if b.bits[word]&bit == 0 {
+			b.bits[word] |= bit
+			added = true
+		}
+	}
+	return added
+}
+
+// contains reports whether every bit of key is set, i.e. whether Add would
+// report it as seen.
+func (b *bloomSeenSet) contains(key string) bool {
+	h1, h2 := bloomHashes(key)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := uint64(0); i < b.hashes; i++ {
+		if word, bit := b.position(h1, h2, i); b.bits[word]&bit == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHashes returns the two hashes the k bit positions are derived from.
+func bloomHashes(key string) (uint64, uint64) {
+	a, c := fnv.New64a(), fnv.New64()
+	a.Write([]byte(key))
+	c.Write([]byte(key))
+	return a.Sum64(), c.Sum64() | 1
+}
+
+func (b *bloomSeenSet) position(h1, h2, i uint64) (word int, bit uint64) {
+	pos := (h1 + i*h2) % b.m
+	return int(pos / 64), 1 << (pos % 64)
+}
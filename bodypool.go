@@ -0,0 +1,40 @@
+package main
+
+import "sync"
+
+// maxPooledBody keeps unusually large bodies from pinning memory in the
+// pool.
+const maxPooledBody = 1 << 20
+
+// bodyPool recycles the buffers getStatusCode copies response bodies into,
+// which would otherwise be one allocation per request. It holds pointers
+// that travel with the body in responseMeta.buf, so putting one back does
+// not allocate.
+var bodyPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 16<<10)
+		return &b
+	},
+}
+
+// acquireBody returns a pooled buffer holding a slice of length n, grown
+// when the pooled one is too small.
+func acquireBody(n int) *[]byte {
+	bp := bodyPool.Get().(*[]byte)
+	if cap(*bp) < n {
+		*bp = make([]byte, n)
+	}
+	*bp = (*bp)[:n]
+	return bp
+}
+
+// releaseBody hands the buffer of a body from getStatusCode back to the
+// pool. The caller must not use the body afterwards; buffers that are never
+// released are simply garbage collected.
+func releaseBody(bp *[]byte) {
+	if bp == nil || cap(*bp) > maxPooledBody {
+		return
+	}
+	*bp = (*bp)[:0]
+	bodyPool.Put(bp)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// Holding more bodies than were ever released makes the pool allocate;
+// after a release every buffer handed out again has the asked length and
+// none share memory with another body still in use.
+func TestBodyPoolExhaustion(t *testing.T) {
+	const held = 64
+	sizes := []int{0, 1, 100, 16 << 10, 16<<10 + 1, 200 << 10, maxPooledBody + 1}
+
+	// check verifies bodies, the first being body number from.
+	check := func(bodies []*[]byte, from int) {
+		t.Helper()
+		for i, bp := range bodies {
+			i += from
+			if want := sizes[i%len(sizes)]; len(*bp) != want {
+				t.Fatalf("body %d has length %d, want %d", i, len(*bp), want)
+			}
+			if !bytes.Equal(*bp, bytes.Repeat([]byte{byte(i)}, len(*bp))) {
+				t.Fatalf("body %d was overwritten through another body", i)
+			}
+		}
+	}
+	acquire := func() []*[]byte {
+		bodies := make([]*[]byte, held)
+		for i := range bodies {
+			bodies[i] = acquireBody(sizes[i%len(sizes)])
+			for j := range *bodies[i] {
+				(*bodies[i])[j] = byte(i)
+			}
+		}
+		return bodies
+	}
+
+	first := acquire()
+	check(first, 0)
+	// Release half, then take a full set again: some come from the pool,
+	// the rest are new.
+	for _, bp := range first[:held/2] {
+		releaseBody(bp)
+	}
+	second := acquire()
+	check(first[held/2:], held/2)
+	check(second, 0)
+	for _, bp := range append(first[held/2:], second...) {
+		releaseBody(bp)
+	}
+	check(acquire(), 0)
+	releaseBody(nil)
+}
+
+// Releasing a body must not allocate, or the pool saves nothing.
+func TestReleaseBodyNoAlloc(t *testing.T) {
+	bp := acquireBody(1024)
+	if n := testing.AllocsPerRun(100, func() {
+		releaseBody(bp)
+		bp = acquireBody(1024)
+	}); n != 0 {
+		t.Errorf("release and acquire allocate %.1f times, want 0", n)
+	}
+}
+
+func BenchmarkGetStatusCode(b *testing.B) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(benchmarkPage))
+	}))
+	defer srv.Close()
+	client := newClient()
+	t := Target{URL: srv.URL}
+	url := srv.URL + "/bench"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _, meta, err := getStatusCode(client, t, url)
+		if err != nil {
+			b.Fatal(err)
+		}
+		releaseBody(meta.buf)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	neturl "net/url"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// bypassTechnique turns a forbidden URL into a variant request: a new URL
+// and any headers to add.
+type bypassTechnique struct {
+	name  string
+	build func(root, path, query string) (string, map[string]string)
+}
+
+// bypassTechniques are tried in order, cheapest and most common first.
+var bypassTechniques = []bypassTechnique{
+	{"trailing-slash", func(root, path, query string) (string, map[string]string) {
+		if strings.HasSuffix(path, "/") {
+			return root + strings.TrimRight(path, "/") + query, nil
+		}
+		return root + path + "/" + query, nil
+	}},
+	{"dot-segment", func(root, path, query string) (string, map[string]string) {
+		return root + strings.TrimRight(path, "/") + "/." + query, nil
+	}},
+	{"case", func(root, path, query string) (string, map[string]string) {
+		i := strings.LastIndex(strings.TrimRight(path, "/"), "/") + 1
+		return root + path[:i] + flipCase(path[i:]) + query, nil
+	}},
+	{"semicolon", func(root, path, query string) (string, map[string]string) {
+		return root + "/..;" + path + query, nil
+	}},
+	{"double-slash", func(root, path, query string) (string, map[string]string) {
+		return root + "/" + path + query, nil
+	}},
+	{"encoded-dot", func(root, path, query string) (string, map[string]string) {
+		return root + "/%2e" + path + query, nil
+	}},
+	{"x-original-url", func(root, path, query string) (string, map[string]string) {
+		return root + "/", map[string]string{"X-Original-URL": path + query}
+	}},
+	{"x-rewrite-url", func(root, path, query string) (string, map[string]string) {
+		return root + "/", map[string]string{"X-Rewrite-URL": path + query}
+	}},
+	{"localhost-headers", func(root, path, query string) (string, map[string]string) {
+		return root + path + query, map[string]string{"X-Forwarded-For": "127.0.0.1", "X-Real-IP": "127.0.0.1", "X-Custom-IP-Authorization": "127.0.0.1"}
+	}},
+}
+
+// bypassClient sends the variants exactly as built; the normal clients would
+// clean up the very path tricks being tried.
+var bypassClient *fasthttp.Client
+
+func newBypassClient() *fasthttp.Client {
+	client := newClient()
+	client.DisablePathNormalizing = true
+	return client
+}
+
+// probeBypass retries a URL that answered 403 with the bypassTechniques, up
+// to -bypass-max of them, and reports every variant that gets a 2xx.
+func probeBypass(t Target, target, host string) {
+	u, err := neturl.Parse(target)
+	if err != nil || u.Host == "" {
+		return
+	}
+	root := u.Scheme + "://" + u.Host
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	query := ""
+	if u.RawQuery != "" {
+		query = "?" + u.RawQuery
+	}
+
+	for i, tech := range bypassTechniques {
+		if i >= *bypassMax || !takeRequest() {
+			return
+		}
+		variantURL, headers := tech.build(root, path, query)
+		variant := t
+		if len(headers) > 0 {
+			variant.Headers = make(map[string]string, len(t.Headers)+len(headers))
+			for k, v := range t.Headers {
+				variant.Headers[k] = v
+			}
+			for k, v := range headers {
+				variant.Headers[k] = v
+			}
+		}
+		status, body, meta, err := getStatusCode(bypassClient, variant, variantURL)
+		if err != nil || status < 200 || status >= 300 {
+			continue
+		}
+		emitLine(fmt.Sprintf("%s %s: %s gave %d", red("[bypass-403]"), target, tech.name, status))
+		title, _ := extractPage(body, 0)
+		writeResult(Result{
+			URL:         variantURL,
+			Host:        host,
+			Status:      status,
+			Length:      len(body),
+			Title:       title,
+			ContentType: meta.ContentType,
+			Tags:        []string{"bypass-403:" + tech.name},
+
+			EffectiveURL: meta.EffectiveURL,
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+// calibration is the per-target filter derived from responses to paths
+// that should not exist. Length and Words are -1 when not filtered on.
+type calibration struct {
+	Status       int
+	Length       int
+	Words        int
+	FilterStatus bool
+}
+
+var calibrations sync.Map // base URL -> *calibration
+
+func calibrationProbes() []string {
+	probes := []string{
+		randomPath(),
+		randomPath() + "/",
+		randomPath() + ".php",
+		randomPath() + ".html",
+	}
+	if *calibrateHard {
+		probes = append(probes,
+			"."+randomPath(),
+			randomPath()+".aspx",
+			randomPath()+".bak",
+			strings.ToUpper(randomPath()),
+			"admin/"+randomPath(),
+			randomPath()+"/"+randomPath(),
+			randomPath()+"%2e",
+			strings.Repeat(randomPath(), 4),
+		)
+	}
+	return probes
+}
+
+func calibrate(urls []Target) {
+	client := newClient()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, *threads)
+	for _, t := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(t Target) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			c := calibrateTarget(client, t)
+			if c == nil {
+				return
+			}
+			calibrations.Store(t.URL, c)
+			logger.Info("calibrated", "target", t.URL, "filter", c.String())
+			emitLine(fmt.Sprintf("%s %s: %s", blue("[calibrate]"), t.URL, c))
+		}(t)
+	}
+	wg.Wait()
+}
+
+func calibrateTarget(client *fasthttp.Client, t Target) *calibration {
+	var statuses, lengths, words []int
+	for _, probe := range calibrationProbes() {
+		if !takeRequest() {
+			break
+		}
+		statusCode, body, _, err := getStatusCode(client, t, probeURL(t.URL, probe))
+		if err != nil {
+			continue
+		}
+		statuses = append(statuses, statusCode)
+		lengths = append(lengths, len(body))
+		words = append(words, len(bytes.Fields(body)))
+	}
+	if len(statuses) == 0 || !allEqual(statuses) || statuses[0] == 404 {
+		return nil
+	}
+
+	c := &calibration{Status: statuses[0], Length: -1, Words: -1}
+	switch {
+	case allEqual(lengths):
+		c.Length = lengths[0]
+	case allEqual(words):
+		c.Words = words[0]
+	case c.Status < 200 || c.Status >= 300:
+		c.FilterStatus = true
+	default:
+		return nil
+	}
+	return c
+}
+
+func (c *calibration) String() string {
+	switch {
+	case c.Length >= 0:
+		return fmt.Sprintf("filtering status %d with length %d", c.Status, c.Length)
+	case c.Words >= 0:
+		return fmt.Sprintf("filtering status %d with %d words", c.Status, c.Words)
+	default:
+		return fmt.Sprintf("filtering status %d", c.Status)
+	}
+}
+
+func (c *calibration) Match(status int, body []byte) bool {
+	if status != c.Status {
+		return false
+	}
+	switch {
+	case c.Length >= 0:
+		return len(body) == c.Length
+	case c.Words >= 0:
+		return len(bytes.Fields(body)) == c.Words
+	default:
+		return c.FilterStatus
+	}
+}
+
+func isCalibrated(baseURL string, status int, body []byte) bool {
+	c, ok := calibrations.Load(baseURL)
+	return ok && c.(*calibration).Match(status, body)
+}
+
+func allEqual(values []int) bool {
+	for _, v := range values[1:] {
+		if v != values[0] {
+			return false
+		}
+	}
+	return true
+}
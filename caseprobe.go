@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+const (
+	caseUnknown = iota
+	caseProbing
+	caseDecided
+)
+
+var (
+	caseMu    sync.Mutex
+	caseState = make(map[string]int)
+)
+
+// probeCase re-requests a matched word with its case flipped to infer whether
+// the host treats paths case-insensitively. Each host is reported once;
+// inconclusive comparisons leave the host to be tried with the next match.
+func probeCase(client *fasthttp.Client, t Target, dir job, status, length int) {
+	alt := dir.mapValues(flipCase)
+	if alt.key() == dir.key() {
+		return
+	}
+
+	host := hostOf(t.URL)
+	caseMu.Lock()
+	if caseState[host] != caseUnknown {
+		caseMu.Unlock()
+		return
+	}
+	caseState[host] = caseProbing
+	caseMu.Unlock()
+
+	verdict := ""
+	if takeRequest() {
+		altStatus, body, _, err := getStatusCode(client, t, alt.URL(t.URL))
+		if err == nil {
+			switch {
+			case altStatus == status && len(body) == length:
+				verdict = "case-insensitive paths (likely IIS/Windows)"
+			case altStatus == 404:
+				verdict = "case-sensitive paths (likely Apache/nginx on Linux)"
+			}
+		}
+	}
+
+	caseMu.Lock()
+	defer caseMu.Unlock()
+	if verdict == "" {
+		caseState[host] = caseUnknown
+		return
+	}
+	caseState[host] = caseDecided
+	emitLine(fmt.Sprintf("%s %s: %s", blue("[case]"), host, verdict))
+}
+
+func flipCase(s string) string {
+	if upper := strings.ToUpper(s); upper != s {
+		return upper
+	}
+	return strings.ToLower(s)
+}
@@ -0,0 +1,17 @@
+package main
+
+import "github.com/valyala/fasthttp"
+
+// newClient returns a client with the options every request shares: the
+// -tls-min/-tls-max/-ciphers/-sni config, the -unix or -connect-timeout
+// dialer and -timeout. All clients come from here, so a new connection
+// option only has to be added once.
+func newClient() *fasthttp.Client {
+	return &fasthttp.Client{
+		Name:         "DirScan",
+		TLSConfig:    clientTLS,
+		Dial:         clientDial,
+		ReadTimeout:  *timeout,
+		WriteTimeout: *timeout,
+	}
+}
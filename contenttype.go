@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// contentKinds lists the kinds printResult tags results with, in legend order.
+var contentKinds = []string{"html", "json", "js", "css", "xml", "text", "image", "binary"}
+
+var kindColors = map[string]func(a ...interface{}) string{
+	"html":   color.New(color.FgWhite).SprintFunc(),
+	"json":   color.New(color.FgCyan, color.Bold).SprintFunc(),
+	"js":     color.New(color.FgYellow, color.Bold).SprintFunc(),
+	"css":    color.New(color.FgHiBlack).SprintFunc(),
+	"xml":    color.New(color.FgCyan).SprintFunc(),
+	"text":   color.New(color.FgWhite).SprintFunc(),
+	"image":  color.New(color.FgHiBlack).SprintFunc(),
+	"binary": color.New(color.FgMagenta).SprintFunc(),
+}
+
+var (
+	kindCountsMu sync.Mutex
+	kindCounts   = make(map[string]int)
+)
+
+// responseContentType returns the Content-Type header, sniffing the body
+// when the server did not send one.
+func responseContentType(header string, body []byte) string {
+	if header != "" || len(body) == 0 {
+		return header
+	}
+	return http.DetectContentType(body)
+}
+
+// contentKind classifies a Content-Type into one of contentKinds.
+func contentKind(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	}
+
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		return "html"
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return "json"
+	case strings.Contains(mediaType, "javascript") || mediaType == "application/ecmascript":
+		return "js"
+	case mediaType == "text/css":
+		return "css"
+	case strings.HasSuffix(mediaType, "/xml") || strings.HasSuffix(mediaType, "+xml"):
+		return "xml"
+	case strings.HasPrefix(mediaType, "text/"):
+		return "text"
+	case strings.HasPrefix(mediaType, "image/"):
+		return "image"
+	}
+	return "binary"
+}
+
+// kindString renders the content kind tag.
+func kindString(kind string) string {
+	if kind == "" {
+		return ""
+	}
+	return kindColors[kind](fmt.Sprintf("[%s]", kind))
+}
+
+// countKind counts a printed result of the given kind for printKindLegend.
+func countKind(kind string) {
+	if kind == "" {
+		return
+	}
+	kindCountsMu.Lock()
+	kindCounts[kind]++
+	kindCountsMu.Unlock()
+}
+
+// printKindLegend prints the colors used for each content kind seen during
+// the scan, with how many printed results had it.
+func printKindLegend() {
+	kindCountsMu.Lock()
+	defer kindCountsMu.Unlock()
+
+	var parts []string
+	for _, kind := range contentKinds {
+		if n := kindCounts[kind]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", kindColors[kind]("["+kind+"]"), n))
+		}
+	}
+	if len(parts) > 0 {
+		fmt.Println("Content types:", strings.Join(parts, "  "))
+	}
+}
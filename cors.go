@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// corsPolicy is what a path answered to a -preflight OPTIONS request.
+type corsPolicy struct {
+	Status           int    `json:"status"`
+	AllowOrigin      string `json:"allow_origin,omitempty"`
+	AllowMethods     string `json:"allow_methods,omitempty"`
+	AllowHeaders     string `json:"allow_headers,omitempty"`
+	AllowCredentials bool   `json:"allow_credentials,omitempty"`
+}
+
+// probePreflight sends a CORS preflight for url as a browser on
+// -preflight-origin would before a -preflight-method request, with the
+// target's headers and auth as for -methods-probe, and returns the policy,
+// or nil when the response has no CORS headers.
+func probePreflight(client *fasthttp.Client, t Target, url string) *corsPolicy {
+	resp, err := sendProbe(client, t, fasthttp.MethodOptions, url, map[string]string{
+		"Origin":                        *preflightOrigin,
+		"Access-Control-Request-Method": *preflightMethod,
+	})
+	if err != nil {
+		return nil
+	}
+	defer fasthttp.ReleaseResponse(resp)
+	p := &corsPolicy{
+		Status:           resp.StatusCode(),
+		AllowOrigin:      string(resp.Header.Peek("Access-Control-Allow-Origin")),
+		AllowMethods:     string(resp.Header.Peek("Access-Control-Allow-Methods")),
+		AllowHeaders:     string(resp.Header.Peek("Access-Control-Allow-Headers")),
+		AllowCredentials: strings.EqualFold(string(resp.Header.Peek("Access-Control-Allow-Credentials")), "true"),
+	}
+	if p.AllowOrigin == "" && p.AllowMethods == "" && p.AllowHeaders == "" && !p.AllowCredentials {
+		return nil
+	}
+	return p
+}
+
+// corsTags names the policy's misconfigurations: any origin, ours echoed
+// back or "null" allowed together with credentials. Browsers refuse the
+// literal "*" with credentials, but a server sending it usually reflects
+// origins elsewhere.
+func corsTags(p *corsPolicy) []string {
+	if p == nil || !p.AllowCredentials {
+		return nil
+	}
+	switch p.AllowOrigin {
+	case "*":
+		return []string{"cors-wildcard-credentials"}
+	case *preflightOrigin, "null":
+		return []string{"cors-reflected-credentials"}
+	}
+	return nil
+}
+
+// corsString renders a policy as "[cors origin=* methods=GET,PUT creds]".
+func corsString(p *corsPolicy) string {
+	parts := []string{"cors"}
+	if p.AllowOrigin != "" {
+		parts = append(parts, "origin="+p.AllowOrigin)
+	}
+	if p.AllowMethods != "" {
+		parts = append(parts, "methods="+strings.ReplaceAll(p.AllowMethods, " ", ""))
+	}
+	if p.AllowHeaders != "" {
+		parts = append(parts, "headers="+strings.ReplaceAll(p.AllowHeaders, " ", ""))
+	}
+	if p.AllowCredentials {
+		parts = append(parts, "creds")
+	}
+	return "[" + strings.Join(parts, " ") + "]"
+}
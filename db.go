@@ -0,0 +1,173 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	dbBatchSize     = 100
+	dbFlushInterval = time.Second
+)
+
+const dbSchema = `CREATE TABLE IF NOT EXISTS results (
+	id        INTEGER PRIMARY KEY AUTOINCREMENT,
+	url       TEXT NOT NULL,
+	host      TEXT NOT NULL,
+	status    INTEGER NOT NULL,
+	length    INTEGER NOT NULL,
+	title     TEXT,
+	timestamp TEXT NOT NULL,
+	tags      TEXT,
+	etag      TEXT,
+	last_modified TEXT,
+	effective_url TEXT
+)`
+
+// dbMigrations add columns introduced after the first schema to existing files.
+var dbMigrations = map[string]string{
+	"etag":          "ALTER TABLE results ADD COLUMN etag TEXT",
+	"last_modified": "ALTER TABLE results ADD COLUMN last_modified TEXT",
+	"effective_url": "ALTER TABLE results ADD COLUMN effective_url TEXT",
+}
+
+// dbWriter stores results in SQLite from a single goroutine, batching
+// inserts into one transaction per batch.
+type dbWriter struct {
+	db      *sql.DB
+	results chan dbRow
+	done    chan struct{}
+}
+
+type dbRow struct {
+	Result
+	At time.Time
+}
+
+func openDBWriter(path string) (*dbWriter, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(dbSchema); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := migrateDB(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	w := &dbWriter{
+		db:      db,
+		results: make(chan dbRow, dbBatchSize*2),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	return w, nil
+}
+
+func migrateDB(db *sql.DB) error {
+	columns, err := dbColumns(db)
+	if err != nil {
+		return err
+	}
+	for column, stmt := range dbMigrations {
+		if columns[column] {
+			continue
+		}
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dbColumns(db *sql.DB) (map[string]bool, error) {
+	rows, err := db.Query("SELECT name FROM pragma_table_info('results')")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[name] = true
+	}
+	return columns, rows.Err()
+}
+
+func (w *dbWriter) Write(r Result) {
+	w.results <- dbRow{Result: r, At: time.Now()}
+}
+
+func (w *dbWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(dbFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]dbRow, 0, dbBatchSize)
+	for {
+		select {
+		case r, ok := <-w.results:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, r)
+			if len(batch) >= dbBatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			w.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+func (w *dbWriter) flush(batch []dbRow) {
+	if len(batch) == 0 {
+		return
+	}
+	if err := w.insert(batch); err != nil {
+		fmt.Println(red("Error writing results to database:"), err)
+	}
+}
+
+func (w *dbWriter) insert(batch []dbRow) error {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare("INSERT INTO results (url, host, status, length, title, timestamp, tags, etag, last_modified, effective_url) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	defer stmt.Close()
+
+	for _, r := range batch {
+		at := r.At.UTC().Format(time.RFC3339)
+		if _, err := stmt.Exec(r.URL, r.Host, r.Status, r.Length, r.Title, at, strings.Join(r.Tags, ","), r.ETag, r.LastModified, r.EffectiveURL); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (w *dbWriter) Close() {
+	close(w.results)
+	<-w.done
+	w.db.Close()
+}
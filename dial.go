@@ -0,0 +1,31 @@
+package main
+
+import (
+	"net"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// clientDial is the dialer every client uses; nil keeps the fasthttp
+// default of a 3 second connect timeout.
+var clientDial fasthttp.DialFunc
+
+// connectDialer bounds only the TCP connect, so a dead host fails fast while
+// -timeout decides how long a live one gets to answer.
+func connectDialer(timeout time.Duration) fasthttp.DialFunc {
+	return func(addr string) (net.Conn, error) {
+		return fasthttp.DialTimeout(addr, timeout)
+	}
+}
+
+// unixDialer connects every request to the -unix socket at path, whatever
+// host the URL names; the host still goes out in the Host header.
+func unixDialer(path string, timeout time.Duration) fasthttp.DialFunc {
+	return func(string) (net.Conn, error) {
+		if timeout > 0 {
+			return net.DialTimeout("unix", path, timeout)
+		}
+		return net.Dial("unix", path)
+	}
+}
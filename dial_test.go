@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// With -unix every request goes to the socket, and the URL host only
+// travels in the Host header.
+func TestScanUnixSocket(t *testing.T) {
+	sock := filepath.Join(t.TempDir(), "http.sock")
+	ln, err := net.Listen("unix", sock)
+	if err != nil {
+		t.Skip("unix sockets unavailable:", err)
+	}
+	var mu sync.Mutex
+	hosts := make(map[string]bool)
+	srv := &fasthttp.Server{Handler: func(ctx *fasthttp.RequestCtx) {
+		mu.Lock()
+		hosts[string(ctx.Host())] = true
+		mu.Unlock()
+		if string(ctx.Path()) != "/app/admin" {
+			ctx.NotFound()
+			return
+		}
+		ctx.SetContentType("text/html")
+		ctx.WriteString("<title>socket admin</title>")
+	}}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Shutdown() })
+
+	clientDial = unixDialer(sock, 0)
+	t.Cleanup(func() { clientDial = nil })
+	startOutputs(t)
+
+	before := matchCount.Load()
+	dirs := []job{{Values: []string{"admin"}}, {Values: []string{"missing"}}}
+	runScan(context.Background(), dirs, []Target{{URL: "http://socket.test/app/"}}, 2)
+	flushOutputs()
+	if n := matchCount.Load() - before; n != 1 {
+		t.Errorf("got %d results, want 1", n)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(hosts) != 1 || !hosts["socket.test"] {
+		t.Errorf("server saw Host %v, want only socket.test", hosts)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/valyala/fasthttp"
+)
+
+type digestCredentials struct {
+	User     string
+	Password string
+}
+
+// digestChallenge is a server's Digest challenge, cached per host so later
+// requests can authenticate up front instead of being challenged each time.
+type digestChallenge struct {
+	Realm     string
+	Nonce     string
+	Opaque    string
+	Qop       string
+	Algorithm string
+	nc        atomic.Uint32
+}
+
+var (
+	digestCreds *digestCredentials
+	digestCache sync.Map // host -> *digestChallenge
+)
+
+func parseDigestCredentials(s string) (*digestCredentials, error) {
+	user, password, ok := strings.Cut(s, ":")
+	if !ok || user == "" {
+		return nil, fmt.Errorf("expected user:pass")
+	}
+	return &digestCredentials{User: user, Password: password}, nil
+}
+
+func digestChallengeFrom(resp *fasthttp.Response) *digestChallenge {
+	if resp.StatusCode() != fasthttp.StatusUnauthorized {
+		return nil
+	}
+	for _, v := range resp.Header.PeekAll("WWW-Authenticate") {
+		if len(v) < 7 || !bytes.EqualFold(v[:7], []byte("Digest ")) {
+			continue
+		}
+		params := parseAuthParams(string(v[7:]))
+		ch := &digestChallenge{
+			Realm:     params["realm"],
+			Nonce:     params["nonce"],
+			Opaque:    params["opaque"],
+			Algorithm: params["algorithm"],
+		}
+		for _, q := range strings.Split(params["qop"], ",") {
+			if strings.TrimSpace(q) == "auth" {
+				ch.Qop = "auth"
+			}
+		}
+		if ch.Nonce != "" {
+			return ch
+		}
+	}
+	return nil
+}
+
+// parseAuthParams splits a comma-separated list of key=value pairs where
+// values may be quoted and contain commas.
+func parseAuthParams(s string) map[string]string {
+	params := make(map[string]string)
+	for s != "" {
+		s = strings.TrimLeft(s, " ,")
+		key, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		rest = strings.TrimLeft(rest, " ")
+
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, s = rest[1:], ""
+			} else {
+				value, s = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, s, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+		params[key] = value
+	}
+	return params
+}
+
+func setDigestAuth(req *fasthttp.Request, ch *digestChallenge) {
+	var newHash func() hash.Hash
+	switch strings.TrimSuffix(strings.ToUpper(ch.Algorithm), "-SESS") {
+	case "SHA-256":
+		newHash = sha256.New
+	default:
+		newHash = md5.New
+	}
+	h := func(s string) string {
+		hh := newHash()
+		hh.Write([]byte(s))
+		return hex.EncodeToString(hh.Sum(nil))
+	}
+
+	uri := string(req.URI().RequestURI())
+	cnonceBytes := make([]byte, 8)
+	rand.Read(cnonceBytes)
+	cnonce := hex.EncodeToString(cnonceBytes)
+	nc := fmt.Sprintf("%08x", ch.nc.Add(1))
+
+	ha1 := h(digestCreds.User + ":" + ch.Realm + ":" + digestCreds.Password)
+	if strings.HasSuffix(strings.ToUpper(ch.Algorithm), "-SESS") {
+		ha1 = h(ha1 + ":" + ch.Nonce + ":" + cnonce)
+	}
+	ha2 := h(string(req.Header.Method()) + ":" + uri)
+
+	var response string
+	if ch.Qop == "auth" {
+		response = h(ha1 + ":" + ch.Nonce + ":" + nc + ":" + cnonce + ":auth:" + ha2)
+	} else {
+		response = h(ha1 + ":" + ch.Nonce + ":" + ha2)
+	}
+
+	auth := fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", response="%s"`,
+		digestCreds.User, ch.Realm, ch.Nonce, uri, response)
+	if ch.Algorithm != "" {
+		auth += ", algorithm=" + ch.Algorithm
+	}
+	if ch.Opaque != "" {
+		auth += fmt.Sprintf(`, opaque="%s"`, ch.Opaque)
+	}
+	if ch.Qop == "auth" {
+		auth += fmt.Sprintf(`, qop=auth, nc=%s, cnonce="%s"`, nc, cnonce)
+	}
+	req.Header.Set("Authorization", auth)
+}
+
+// doDigest sends req, answering a Digest challenge once if the cached nonce
+// is missing or no longer accepted.
+func doDigest(client *fasthttp.Client, req *fasthttp.Request, resp *fasthttp.Response) error {
+	host := string(req.URI().Host())
+	if ch, ok := digestCache.Load(host); ok {
+		setDigestAuth(req, ch.(*digestChallenge))
+	}
+	if err := client.Do(req, resp); err != nil {
+		return err
+	}
+
+	ch := digestChallengeFrom(resp)
+	if ch == nil {
+		return nil
+	}
+	digestCache.Store(host, ch)
+	setDigestAuth(req, ch)
+	return client.Do(req, resp)
+}
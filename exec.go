@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// execHook runs -exec for every result with at most -exec-workers
+// commands at once.
+//
+// The template is split into arguments once and placeholders are filled in
+// per argument, and the command is run directly rather than through a shell,
+// so a malicious title cannot inject shell syntax. The substituted values
+// still come from the target, and the command must treat them as untrusted.
+type execHook struct {
+	argv []string
+	sem  chan struct{}
+	wg   sync.WaitGroup
+
+	logMu sync.Mutex
+	log   *os.File
+}
+
+func newExecHook(template string, workers int, logPath string) (*execHook, error) {
+	argv, err := splitArgs(template)
+	if err != nil {
+		return nil, err
+	}
+	if len(argv) == 0 {
+		return nil, errors.New("empty command")
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	h := &execHook{argv: argv, sem: make(chan struct{}, workers)}
+	if logPath != "" {
+		h.log, err = os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func (h *execHook) Write(r Result) {
+	replacer := strings.NewReplacer(
+		"{url}", r.URL,
+		"{status}", strconv.Itoa(r.Status),
+		"{title}", r.Title,
+		"{host}", r.Host,
+	)
+	args := make([]string, len(h.argv))
+	for i, a := range h.argv {
+		args[i] = replacer.Replace(a)
+	}
+
+	h.wg.Add(1)
+	h.sem <- struct{}{}
+	go func() {
+		defer func() {
+			<-h.sem
+			h.wg.Done()
+		}()
+		out, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+		// Not emitLine: the output goroutine may itself be waiting in
+		// Write for this command's slot, so queueing behind it can deadlock.
+		if err != nil {
+			printLine(fmt.Sprint(red("Exec hook failed: "), strings.Join(args, " "), " ", err))
+		}
+		if h.log != nil {
+			h.logMu.Lock()
+			fmt.Fprintf(h.log, "$ %s\n%s\n", strings.Join(args, " "), out)
+			h.logMu.Unlock()
+		}
+	}()
+}
+
+func (h *execHook) Close() {
+	h.wg.Wait()
+	if h.log != nil {
+		h.log.Close()
+	}
+}
+
+// splitArgs splits a command line on whitespace, honouring single and double quotes.
+func splitArgs(s string) ([]string, error) {
+	var args []string
+	var cur strings.Builder
+	var quote rune
+	inArg := false
+
+	for _, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, cur.String())
+				cur.Reset()
+				inArg = false
+			}
+		default:
+			cur.WriteRune(r)
+			inArg = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inArg {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
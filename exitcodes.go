@@ -0,0 +1,27 @@
+package main
+
+import "sync/atomic"
+
+// Process exit codes, for scripts and CI pipelines.
+const (
+	exitFound       = 0 // the scan finished with at least one result
+	exitNotFound    = 1 // the scan finished without results
+	exitFatal       = 2 // invalid options or an I/O error before or during the scan
+	exitInterrupted = 3 // stopped by Ctrl-C or -max-runtime
+)
+
+// resultsFound is set by the first result written.
+var resultsFound atomic.Bool
+
+// scanExitCode picks the exit code for a scan that ran to its end or was
+// stopped; interrupted wins, since a partial scan proves nothing either way.
+func scanExitCode(interrupted bool) int {
+	switch {
+	case interrupted:
+		return exitInterrupted
+	case resultsFound.Load():
+		return exitFound
+	default:
+		return exitNotFound
+	}
+}
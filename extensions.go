@@ -0,0 +1,112 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+var (
+	// extensions are appended to every word of the last wordlist keyword,
+	// which also keeps the bare word.
+	extensions []string
+	// extStatus holds the statuses accepted for extensions given as
+	// ext:codes; other extensions use the global status filters.
+	extStatus = make(map[string]map[int]bool)
+)
+
+// parseExtensions reads every -x value: "php,html" adds extensions, and
+// "bak:200" or "php:200,403" adds one extension with its own statuses.
+func parseExtensions(specs []string) error {
+	seen := make(map[string]bool)
+	add := func(ext string) error {
+		ext = strings.TrimPrefix(strings.TrimSpace(ext), ".")
+		if ext == "" {
+			return fmt.Errorf("empty extension")
+		}
+		if !seen[ext] {
+			seen[ext] = true
+			extensions = append(extensions, ext)
+		}
+		return nil
+	}
+	for _, spec := range specs {
+		ext, codes, ok := strings.Cut(spec, ":")
+		if !ok {
+			for _, e := range strings.Split(spec, ",") {
+				if err := add(e); err != nil {
+					return fmt.Errorf("%q: %w", spec, err)
+				}
+			}
+			continue
+		}
+		if err := add(ext); err != nil {
+			return fmt.Errorf("%q: %w", spec, err)
+		}
+		statuses, err := parseStatusList(codes)
+		if err != nil {
+			return fmt.Errorf("%q: %w", spec, err)
+		}
+		extStatus[strings.TrimPrefix(strings.TrimSpace(ext), ".")] = statuses
+	}
+	return nil
+}
+
+// readExtensionsFile reads -X: one -x value per line, blank lines skipped.
+func readExtensionsFile(path string) ([]string, error) {
+	file, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var specs []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			specs = append(specs, line)
+		}
+	}
+	return specs, scanner.Err()
+}
+
+// addExtensions returns words followed by each word with every -x extension.
+func addExtensions(words []string) []string {
+	if len(extensions) == 0 {
+		return words
+	}
+	out := make([]string, 0, len(words)*(len(extensions)+1))
+	for _, w := range words {
+		out = append(out, w)
+		for _, ext := range extensions {
+			out = append(out, w+"."+ext)
+		}
+	}
+	return out
+}
+
+// ext returns the -x extension the job's last word ends in, if any.
+func (j job) ext() string {
+	if len(j.Values) == 0 {
+		return ""
+	}
+	word := strings.TrimSuffix(j.Values[len(j.Values)-1], *suffix)
+	best := ""
+	for _, ext := range extensions {
+		if strings.HasSuffix(word, "."+ext) && len(ext) > len(best) {
+			best = ext
+		}
+	}
+	return best
+}
+
+// jobStatusAllowed applies the statuses given for the job's extension, or
+// the global status filters when it has none.
+func jobStatusAllowed(j job, status int) bool {
+	if len(extStatus) > 0 {
+		if codes, ok := extStatus[j.ext()]; ok {
+			return codes[status]
+		}
+	}
+	return statusAllowed(status)
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// parseStatusList parses a comma-separated list of status codes such as "401,403".
+func parseStatusList(s string) (map[int]bool, error) {
+	codes := make(map[int]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		code, err := strconv.Atoi(part)
+		if err != nil || code < 100 || code > 999 {
+			return nil, fmt.Errorf("invalid status code %q", part)
+		}
+		codes[code] = true
+	}
+	return codes, nil
+}
+
+var matchStatus, filterStatus map[int]bool
+
+func parseStatusFilters() error {
+	var err error
+	if *matchCodes != "" {
+		if matchStatus, err = parseStatusList(*matchCodes); err != nil {
+			return fmt.Errorf("-mc: %w", err)
+		}
+	}
+	if *filterCodes != "" {
+		if filterStatus, err = parseStatusList(*filterCodes); err != nil {
+			return fmt.Errorf("-fc: %w", err)
+		}
+	}
+	if *minStatus > 0 && *maxStatus > 0 && *minStatus > *maxStatus {
+		return fmt.Errorf("-min-status %d is above -max-status %d", *minStatus, *maxStatus)
+	}
+	return nil
+}
+
+// statusAllowed applies -mc, -min-status/-max-status and -fc. Without any
+// match rule only 404s are hidden, unless -show-404 is set. -fc always
+// applies, so -show-404 -fc 404 still hides them.
+func statusAllowed(status int) bool {
+	if filterStatus[status] {
+		return false
+	}
+	if matchStatus == nil && *minStatus == 0 && *maxStatus == 0 {
+		return status != 404 || *show404
+	}
+	if matchStatus != nil && !matchStatus[status] {
+		return false
+	}
+	if *minStatus > 0 && status < *minStatus {
+		return false
+	}
+	return *maxStatus == 0 || status <= *maxStatus
+}
+
+var (
+	titleSubstrings []string
+	titleRegex      *regexp.Regexp
+)
+
+func parseTitleFilters() error {
+	for _, s := range strings.Split(*filterTitle, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			titleSubstrings = append(titleSubstrings, strings.ToLower(s))
+		}
+	}
+	if *filterTitleRegex != "" {
+		re, err := regexp.Compile(*filterTitleRegex)
+		if err != nil {
+			return err
+		}
+		titleRegex = re
+	}
+	return nil
+}
+
+// titleFiltered reports whether title matches -filter-by-title (case-insensitive
+// substrings) or -filter-by-title-regex.
+func titleFiltered(title string) bool {
+	lower := strings.ToLower(title)
+	for _, s := range titleSubstrings {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return titleRegex != nil && titleRegex.MatchString(title)
+}
+
+var redirectPatterns []*regexp.Regexp
+
+func parseRedirectFilters() error {
+	for _, s := range ignoreRedirectTo {
+		re, err := regexp.Compile(s)
+		if err != nil {
+			return err
+		}
+		redirectPatterns = append(redirectPatterns, re)
+	}
+	return nil
+}
+
+// redirectFiltered reports whether the response redirects, or was followed
+// through a redirect, to a location matching -ignore-redirect-to.
+func redirectFiltered(meta responseMeta) bool {
+	if len(redirectPatterns) == 0 {
+		return false
+	}
+	locations := []string{meta.Location}
+	if len(meta.Redirects) > 1 {
+		for _, hop := range meta.Redirects[1:] {
+			locations = append(locations, hop.URL)
+		}
+	}
+	for _, loc := range locations {
+		if loc == "" {
+			continue
+		}
+		for _, re := range redirectPatterns {
+			if re.MatchString(loc) {
+				return true
+			}
+		}
+	}
+	return false
+}
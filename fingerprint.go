@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// techSignature identifies a technology by a response header matching a
+// pattern, a cookie name or a body pattern; any one is enough.
+type techSignature struct {
+	name   string
+	header string
+	value  *regexp.Regexp
+	cookie string
+	body   *regexp.Regexp
+}
+
+var techSignatures = []techSignature{
+	{name: "WordPress", body: regexp.MustCompile(`/wp-(?:content|includes)/|<meta name="generator" content="WordPress`)},
+	{name: "Drupal", header: "X-Generator", value: regexp.MustCompile(`(?i)drupal`)},
+	{name: "Drupal", header: "X-Drupal-Cache", value: regexp.MustCompile(`.`)},
+	{name: "Drupal", body: regexp.MustCompile(`Drupal\.settings|/sites/default/files/`)},
+	{name: "Joomla", body: regexp.MustCompile(`/media/jui/|<meta name="generator" content="Joomla`)},
+	{name: "Django", cookie: "csrftoken"},
+	{name: "Django", body: regexp.MustCompile(`csrfmiddlewaretoken`)},
+	{name: "Laravel", cookie: "laravel_session"},
+	{name: "Ruby on Rails", header: "X-Runtime", value: regexp.MustCompile(`^[0-9.]+$`)},
+	{name: "Ruby on Rails", body: regexp.MustCompile(`<meta name="csrf-param" content="authenticity_token"`)},
+	{name: "Spring", body: regexp.MustCompile(`Whitelabel Error Page`)},
+	{name: "Next.js", header: "X-Powered-By", value: regexp.MustCompile(`(?i)next\.js`)},
+	{name: "Next.js", body: regexp.MustCompile(`<script id="__NEXT_DATA__"`)},
+	{name: "Express", header: "X-Powered-By", value: regexp.MustCompile(`(?i)^express`)},
+	{name: "PHP", header: "X-Powered-By", value: regexp.MustCompile(`(?i)php`)},
+	{name: "PHP", cookie: "PHPSESSID"},
+	{name: "ASP.NET", header: "X-Aspnet-Version", value: regexp.MustCompile(`.`)},
+	{name: "ASP.NET", header: "X-Powered-By", value: regexp.MustCompile(`(?i)asp\.net`)},
+	{name: "ASP.NET", cookie: "ASP.NET_SessionId"},
+	{name: "ASP.NET", body: regexp.MustCompile(`name="__VIEWSTATE"`)},
+	{name: "Java", cookie: "JSESSIONID"},
+	{name: "Tomcat", body: regexp.MustCompile(`<h3>Apache Tomcat/`)},
+	{name: "nginx", header: "Server", value: regexp.MustCompile(`(?i)^nginx`)},
+	{name: "Apache", header: "Server", value: regexp.MustCompile(`(?i)^apache`)},
+	{name: "IIS", header: "Server", value: regexp.MustCompile(`(?i)^microsoft-iis`)},
+	{name: "Cloudflare", header: "Cf-Ray", value: regexp.MustCompile(`.`)},
+}
+
+// fingerprintSamples is how many responses per host are examined; the stack
+// rarely changes from one path to the next.
+const fingerprintSamples = 20
+
+type hostTech struct {
+	mu      sync.Mutex
+	samples int
+	found   map[string]bool
+}
+
+var hostTechs sync.Map // host -> *hostTech
+
+// fingerprint checks a response from host against techSignatures and
+// returns everything detected on the host so far.
+func fingerprint(host string, headers map[string][]string, body []byte) []string {
+	v, _ := hostTechs.LoadOrStore(host, &hostTech{found: make(map[string]bool)})
+	h := v.(*hostTech)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.samples < fingerprintSamples {
+		h.samples++
+		cookies := cookieNames(headers["Set-Cookie"])
+		for _, sig := range techSignatures {
+			if !h.found[sig.name] && sig.match(headers, cookies, body) {
+				h.found[sig.name] = true
+			}
+		}
+	}
+	return sortedTech(h.found)
+}
+
+func (s techSignature) match(headers map[string][]string, cookies map[string]bool, body []byte) bool {
+	switch {
+	case s.header != "":
+		for _, v := range headers[s.header] {
+			if s.value.MatchString(v) {
+				return true
+			}
+		}
+		return false
+	case s.cookie != "":
+		return cookies[s.cookie]
+	default:
+		return s.body.Match(body)
+	}
+}
+
+func cookieNames(setCookies []string) map[string]bool {
+	names := make(map[string]bool, len(setCookies))
+	for _, c := range setCookies {
+		name, _, _ := strings.Cut(c, "=")
+		names[strings.TrimSpace(name)] = true
+	}
+	return names
+}
+
+func sortedTech(found map[string]bool) []string {
+	if len(found) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// printFingerprints prints the detected stack of every host, once.
+func printFingerprints() {
+	var lines []string
+	hostTechs.Range(func(k, v any) bool {
+		h := v.(*hostTech)
+		h.mu.Lock()
+		if tech := sortedTech(h.found); len(tech) > 0 {
+			lines = append(lines, fmt.Sprintf("  %s: %s", k, strings.Join(tech, ", ")))
+		}
+		h.mu.Unlock()
+		return true
+	})
+	if len(lines) == 0 {
+		return
+	}
+	sort.Strings(lines)
+	fmt.Println(blue("Fingerprints:"))
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// textWriter writes results as plain text lines, like the terminal output
+// without color.
+type textWriter struct {
+	file *os.File
+	w    *bufio.Writer
+}
+
+func newTextWriter(path string) (*textWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &textWriter{file: file, w: bufio.NewWriter(file)}, nil
+}
+
+func (t *textWriter) Write(r Result) {
+	fmt.Fprintln(t.w, textLine(r))
+}
+
+func (t *textWriter) Close() {
+	t.w.Flush()
+	t.file.Close()
+}
+
+// grepWriter writes one URL|status|length|title|effective URL line per
+// result for grep and awk, with a |time field added by -timestamps. A '|' inside a field is written as "\|" and a
+// backslash as "\\"; line breaks become spaces.
+type grepWriter struct {
+	file *os.File
+	w    *bufio.Writer
+}
+
+var grepEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", " ", "\n", " ", "\r", " ")
+
+func newGrepWriter(path string) (*grepWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &grepWriter{file: file, w: bufio.NewWriter(file)}, nil
+}
+
+func (g *grepWriter) Write(r Result) {
+	fmt.Fprintf(g.w, "%s|%d|%d|%s|%s", grepEscaper.Replace(r.URL), r.Status, r.Length, grepEscaper.Replace(r.Title), grepEscaper.Replace(r.EffectiveURL))
+	if r.Time != "" {
+		fmt.Fprint(g.w, "|"+r.Time)
+	}
+	fmt.Fprintln(g.w)
+}
+
+func (g *grepWriter) Close() {
+	g.w.Flush()
+	g.file.Close()
+}
+
+// jsonWriter writes one JSON object per result.
+type jsonWriter struct {
+	file *os.File
+	w    *bufio.Writer
+	enc  *json.Encoder
+}
+
+func newJSONWriter(path string) (*jsonWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(file)
+	return &jsonWriter{file: file, w: w, enc: json.NewEncoder(w)}, nil
+}
+
+func (j *jsonWriter) Write(r Result) {
+	if err := j.enc.Encode(r); err != nil {
+		fmt.Println(red("Error writing JSON result:"), err)
+	}
+}
+
+func (j *jsonWriter) Close() {
+	j.w.Flush()
+	j.file.Close()
+}
+
+var csvHeader = []string{"url", "host", "status", "length", "title", "tags", "methods", "etag", "last_modified", "headers", "effective_url", "time"}
+
+// csvWriter writes results as CSV with a fixed column set, leaving cells
+// empty rather than dropping columns so files from different runs line up.
+type csvWriter struct {
+	file *os.File
+	w    *csv.Writer
+}
+
+func newCSVWriter(path string) (*csvWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := csv.NewWriter(file)
+	if err := w.Write(csvHeader); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &csvWriter{file: file, w: w}, nil
+}
+
+func (c *csvWriter) Write(r Result) {
+	record := []string{
+		r.URL,
+		r.Host,
+		strconv.Itoa(r.Status),
+		strconv.Itoa(r.Length),
+		r.Title,
+		strings.Join(r.Tags, ","),
+		strings.Join(r.Methods, ","),
+		r.ETag,
+		r.LastModified,
+		csvHeaders(r.Headers),
+		r.EffectiveURL,
+		r.Time,
+	}
+	if err := c.w.Write(record); err != nil {
+		fmt.Println(red("Error writing CSV result:"), err)
+	}
+}
+
+func (c *csvWriter) Close() {
+	c.w.Flush()
+	c.file.Close()
+}
+
+func csvHeaders(headers map[string]string) string {
+	if len(headers) == 0 {
+		return ""
+	}
+	return strings.Trim(headersString(headers), "[]")
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/valyala/fasthttp"
+)
+
+// HAR 1.2 structures, only the fields dirscan can fill.
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harTimings struct {
+	Send    int     `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive int     `json:"receive"`
+}
+
+type harEntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+// harWriter streams -har entries to disk as they happen, so memory does not
+// grow with the scan. The closing brackets are written by Close.
+type harWriter struct {
+	mu     sync.Mutex
+	file   *os.File
+	w      *bufio.Writer
+	n      int
+	closed bool
+}
+
+var harLog *harWriter
+
+func newHARWriter(path string) (*harWriter, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	h := &harWriter{file: file, w: bufio.NewWriter(file)}
+	h.w.WriteString(`{"log":{"version":"1.2","creator":{"name":"dirscan","version":"1.0"},"entries":[` + "\n")
+	return h, nil
+}
+
+// add records one request/response exchange, with the response body
+// decoded.
+func (h *harWriter) add(req *fasthttp.Request, resp *fasthttp.Response, start time.Time, elapsed time.Duration) {
+	body, err := resp.BodyUncompressed()
+	if err != nil {
+		body = resp.Body()
+	}
+	ms := float64(elapsed.Microseconds()) / 1000
+	e := harEntry{
+		StartedDateTime: start.Format(time.RFC3339Nano),
+		Time:            ms,
+		Request: harRequest{
+			Method:      string(req.Header.Method()),
+			URL:         req.URI().String(),
+			HTTPVersion: string(req.Header.Protocol()),
+			Cookies:     []harNameValue{},
+			Headers:     []harNameValue{},
+			QueryString: []harNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(req.Body()),
+		},
+		Response: harResponse{
+			Status:      resp.StatusCode(),
+			StatusText:  fasthttp.StatusMessage(resp.StatusCode()),
+			HTTPVersion: string(resp.Header.Protocol()),
+			Cookies:     []harNameValue{},
+			Headers:     []harNameValue{},
+			Content: harContent{
+				Size:     len(body),
+				MimeType: string(resp.Header.Peek("Content-Type")),
+			},
+			RedirectURL: string(resp.Header.Peek("Location")),
+			HeadersSize: -1,
+			BodySize:    len(resp.Body()),
+		},
+		Timings: harTimings{Send: 0, Wait: ms, Receive: 0},
+	}
+	req.Header.VisitAll(func(k, v []byte) {
+		e.Request.Headers = append(e.Request.Headers, harNameValue{string(k), string(v)})
+	})
+	req.Header.VisitAllCookie(func(k, v []byte) {
+		e.Request.Cookies = append(e.Request.Cookies, harNameValue{string(k), string(v)})
+	})
+	req.URI().QueryArgs().VisitAll(func(k, v []byte) {
+		e.Request.QueryString = append(e.Request.QueryString, harNameValue{string(k), string(v)})
+	})
+	resp.Header.VisitAll(func(k, v []byte) {
+		e.Response.Headers = append(e.Response.Headers, harNameValue{string(k), string(v)})
+	})
+	if utf8.Valid(body) {
+		e.Response.Content.Text = string(body)
+	} else {
+		e.Response.Content.Text = base64.StdEncoding.EncodeToString(body)
+		e.Response.Content.Encoding = "base64"
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return
+	}
+	if h.n > 0 {
+		h.w.WriteString(",\n")
+	}
+	h.w.Write(line)
+	h.n++
+}
+
+func (h *harWriter) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	h.w.WriteString("\n]}}\n")
+	h.w.Flush()
+	h.file.Close()
+}
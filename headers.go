@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// headerRule matches a response header by presence, or by value when a
+// regular expression is given ("Name:regex").
+type headerRule struct {
+	Name  string
+	Value *regexp.Regexp
+}
+
+var (
+	displayHeaders []string
+	matchHeaders   []headerRule
+	filterHeaders  []headerRule
+)
+
+func parseHeaderRule(s string) (headerRule, error) {
+	name, value, hasValue := strings.Cut(s, ":")
+	rule := headerRule{Name: http.CanonicalHeaderKey(strings.TrimSpace(name))}
+	if rule.Name == "" {
+		return rule, fmt.Errorf("missing header name in %q", s)
+	}
+	if hasValue {
+		re, err := regexp.Compile(strings.TrimSpace(value))
+		if err != nil {
+			return rule, err
+		}
+		rule.Value = re
+	}
+	return rule, nil
+}
+
+func parseHeaderOptions() error {
+	for _, h := range strings.Split(*showHeaders, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			displayHeaders = append(displayHeaders, http.CanonicalHeaderKey(h))
+		}
+	}
+	for _, s := range matchHeaderFlags {
+		rule, err := parseHeaderRule(s)
+		if err != nil {
+			return err
+		}
+		matchHeaders = append(matchHeaders, rule)
+	}
+	for _, s := range filterHeaderFlags {
+		rule, err := parseHeaderRule(s)
+		if err != nil {
+			return err
+		}
+		filterHeaders = append(filterHeaders, rule)
+	}
+	return nil
+}
+
+func needHeaders() bool {
+	return len(displayHeaders) > 0 || len(matchHeaders) > 0 || len(filterHeaders) > 0 || *fingerprintMode || *autoWordlist
+}
+
+// collectHeaders copies every response header, joining repeated ones.
+func collectHeaders(resp *fasthttp.Response) map[string][]string {
+	headers := make(map[string][]string)
+	resp.Header.VisitAll(func(key, value []byte) {
+		k := http.CanonicalHeaderKey(string(key))
+		headers[k] = append(headers[k], string(value))
+	})
+	return headers
+}
+
+func (r headerRule) match(headers map[string][]string) bool {
+	values, ok := headers[r.Name]
+	if !ok {
+		return false
+	}
+	if r.Value == nil {
+		return true
+	}
+	for _, v := range values {
+		if r.Value.MatchString(v) {
+			return true
+		}
+	}
+	return false
+}
+
+// headersFiltered applies -mh (at least one must match) and -fh (none may match).
+func headersFiltered(headers map[string][]string) bool {
+	for _, r := range filterHeaders {
+		if r.match(headers) {
+			return true
+		}
+	}
+	if len(matchHeaders) == 0 {
+		return false
+	}
+	for _, r := range matchHeaders {
+		if r.match(headers) {
+			return false
+		}
+	}
+	return true
+}
+
+// shownHeaders picks the -show-headers values present in headers.
+func shownHeaders(headers map[string][]string) map[string]string {
+	if len(displayHeaders) == 0 {
+		return nil
+	}
+	shown := make(map[string]string)
+	for _, name := range displayHeaders {
+		if values, ok := headers[name]; ok {
+			shown[name] = strings.Join(values, "; ")
+		}
+	}
+	return shown
+}
+
+func headersString(headers map[string]string) string {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + ": " + headers[name]
+	}
+	return "[" + strings.Join(parts, " | ") + "]"
+}
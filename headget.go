@@ -0,0 +1,55 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+// headUnsupported records hosts that answered HEAD with 405 or 501, so later
+// requests to them go straight to GET.
+var headUnsupported sync.Map
+
+// fetchResponse is what workers call to request a path; tests replace it to
+// stand in for the network.
+var fetchResponse = fetch
+
+// fetch requests target. With -head-then-get a HEAD is sent first and the
+// GET for the body is only issued when the status passes the status
+// filters. meta.Via
+// records which requests produced the result.
+func fetch(client *fasthttp.Client, t Target, dir job, target, host string) (int, []byte, responseMeta, error) {
+	if !*headThenGet || (t.Method != "" && t.Method != fasthttp.MethodGet) {
+		return getStatusCode(client, t, target)
+	}
+	if _, ok := headUnsupported.Load(host); ok {
+		status, body, meta, err := getStatusCode(client, t, target)
+		meta.Via = "GET"
+		return status, body, meta, err
+	}
+
+	head := t
+	head.Method = fasthttp.MethodHead
+	status, _, headMeta, err := getStatusCode(client, head, target)
+	headMeta.Via = "HEAD"
+	if err != nil || headMeta.RetryAfter > 0 {
+		return status, nil, headMeta, err
+	}
+	if status == fasthttp.StatusMethodNotAllowed || status == fasthttp.StatusNotImplemented {
+		headUnsupported.Store(host, true)
+		status, body, meta, err := getStatusCode(client, t, target)
+		meta.Via = "GET"
+		return status, body, meta, err
+	}
+	if !jobStatusAllowed(dir, status) {
+		return status, nil, headMeta, nil
+	}
+
+	getStatus, body, meta, err := getStatusCode(client, t, target)
+	if err != nil {
+		// Keep what HEAD found rather than losing the hit.
+		return status, nil, headMeta, nil
+	}
+	meta.Via = "HEAD+GET"
+	return getStatus, body, meta, nil
+}
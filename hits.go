@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+type hostHits struct {
+	count  atomic.Int64
+	logged atomic.Bool
+}
+
+var hitCounts sync.Map // host -> *hostHits
+
+func hitsFor(host string) *hostHits {
+	h, _ := hitCounts.LoadOrStore(host, &hostHits{})
+	return h.(*hostHits)
+}
+
+// claimHit counts a matched result for host and reports whether it is still
+// within -max-hits-per-host, so requests already in flight when the cap is
+// reached do not push the host past it.
+func claimHit(host string) bool {
+	h := hitsFor(host)
+	n := h.count.Add(1)
+	if n >= int64(*maxHitsPerHost) && h.logged.CompareAndSwap(false, true) {
+		emitLine(fmt.Sprintf("%s %s reached %d hits, likely a catch-all; skipping its remaining paths",
+			yellow("[max-hits]"), host, *maxHitsPerHost))
+		logger.Info("host reached max hits, skipping it", "host", host, "hits", *maxHitsPerHost)
+	}
+	return n <= int64(*maxHitsPerHost)
+}
+
+func hostCapped(host string) bool {
+	return *maxHitsPerHost > 0 && hitsFor(host).count.Load() >= int64(*maxHitsPerHost)
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	neturl "net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+// hstsHosts holds the hostnames that sent Strict-Transport-Security; their
+// http:// URLs are scanned over https:// from then on.
+var hstsHosts sync.Map // hostname -> bool
+
+// noteHSTS records the Strict-Transport-Security header of a response; a
+// max-age of 0 withdraws the upgrade. As RFC 6797 requires, the header is
+// only honoured over https, and one without a valid max-age is ignored.
+func noteHSTS(uri *fasthttp.URI, resp *fasthttp.Response) {
+	if *noHSTS || string(uri.Scheme()) != "https" {
+		return
+	}
+	maxAge, ok := hstsMaxAge(string(resp.Header.Peek("Strict-Transport-Security")))
+	if !ok {
+		return
+	}
+	host := hostnameOf(string(uri.Host()))
+	if maxAge == 0 {
+		hstsHosts.Delete(host)
+		return
+	}
+	if _, loaded := hstsHosts.LoadOrStore(host, true); !loaded && *verbose {
+		fmt.Fprintf(os.Stderr, "%s %s sent Strict-Transport-Security, switching to https\n", blue("[hsts]"), host)
+	}
+}
+
+// hstsMaxAge returns the max-age directive of a Strict-Transport-Security
+// value, reporting false when there is none or it is not a number.
+func hstsMaxAge(sts string) (int64, bool) {
+	for _, directive := range strings.Split(sts, ";") {
+		name, value, _ := strings.Cut(directive, "=")
+		if !strings.EqualFold(strings.TrimSpace(name), "max-age") {
+			continue
+		}
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		if value == "" || strings.Trim(value, "0123456789") != "" {
+			return 0, false
+		}
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		return n, true
+	}
+	return 0, false
+}
+
+// hstsUpgrade rewrites an http:// URL to https:// when its host sent HSTS.
+// An explicit :80 is dropped; other ports are kept.
+func hstsUpgrade(rawURL string) string {
+	rest, ok := strings.CutPrefix(rawURL, "http://")
+	if !ok {
+		return rawURL
+	}
+	end := strings.IndexAny(rest, "/?#")
+	if end < 0 {
+		end = len(rest)
+	}
+	hostport := rest[:end]
+	if _, ok := hstsHosts.Load(hostnameOf(hostport)); !ok {
+		return rawURL
+	}
+	return "https://" + strings.TrimSuffix(hostport, ":80") + rest[end:]
+}
+
+func hostnameOf(hostport string) string {
+	u := neturl.URL{Host: hostport}
+	return u.Hostname()
+}
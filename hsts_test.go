@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+func TestHSTSMaxAge(t *testing.T) {
+	tests := []struct {
+		sts  string
+		want int64
+		ok   bool
+	}{
+		{"max-age=31536000", 31536000, true},
+		{"max-age=31536000; includeSubDomains", 31536000, true},
+		{"includeSubDomains; Max-Age = \"600\"; preload", 600, true},
+		{"max-age=0", 0, true},
+		{"max-age=0;", 0, true},
+		{"max-age=01", 1, true},
+		{"max-age=00", 0, true},
+		{"max-age=", 0, false},
+		{"max-age=-1", 0, false},
+		{"max-age=+5", 0, false},
+		{"max-age=1d", 0, false},
+		{"includeSubDomains", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := hstsMaxAge(tt.sts)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("hstsMaxAge(%q) = %d, %v, want %d, %v", tt.sts, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+// Strict-Transport-Security is only honoured when it arrives over https.
+func TestNoteHSTS(t *testing.T) {
+	t.Cleanup(func() {
+		hstsHosts.Range(func(k, _ any) bool { hstsHosts.Delete(k); return true })
+	})
+	note := func(url, sts string) {
+		uri := fasthttp.AcquireURI()
+		defer fasthttp.ReleaseURI(uri)
+		uri.Parse(nil, []byte(url))
+		resp := fasthttp.AcquireResponse()
+		defer fasthttp.ReleaseResponse(resp)
+		resp.Header.Set("Strict-Transport-Security", sts)
+		noteHSTS(uri, resp)
+	}
+	upgraded := func(host string) bool {
+		_, ok := hstsHosts.Load(host)
+		return ok
+	}
+
+	note("http://plain.test/", "max-age=600")
+	if upgraded("plain.test") {
+		t.Error("HSTS over plain http was honoured")
+	}
+	note("https://secure.test/", "max-age=600")
+	if !upgraded("secure.test") {
+		t.Error("HSTS over https was ignored")
+	}
+	note("https://secure.test/", "max-age=01")
+	if !upgraded("secure.test") {
+		t.Error("max-age=01 withdrew the upgrade")
+	}
+	note("http://secure.test/", "max-age=0")
+	if !upgraded("secure.test") {
+		t.Error("max-age=0 over plain http withdrew the upgrade")
+	}
+	note("https://secure.test/", "max-age=0;")
+	if upgraded("secure.test") {
+		t.Error("max-age=0 over https did not withdraw the upgrade")
+	}
+}
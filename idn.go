@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// asciiURL converts an internationalized hostname in rawURL to punycode for
+// the request line and Host header; display keeps the original form. URLs
+// that are already ASCII, or fail conversion, are returned unchanged.
+func asciiURL(rawURL string) string {
+	start := strings.Index(rawURL, "://")
+	if start < 0 {
+		return rawURL
+	}
+	start += 3
+	end := strings.IndexAny(rawURL[start:], "/?#")
+	if end < 0 {
+		end = len(rawURL)
+	} else {
+		end += start
+	}
+
+	authority := rawURL[start:end]
+	userinfo := ""
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		userinfo, authority = authority[:i+1], authority[i+1:]
+	}
+	host, port := authority, ""
+	if i := strings.LastIndex(authority, ":"); i >= 0 && !strings.HasSuffix(authority, "]") {
+		host, port = authority[:i], authority[i:]
+	}
+	if isASCII(host) {
+		return rawURL
+	}
+	ascii, err := idna.Lookup.ToASCII(host)
+	if err != nil {
+		return rawURL
+	}
+	return rawURL[:start] + userinfo + ascii + port + rawURL[end:]
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestASCIIURL(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"idn host", "http://例え.jp/パス?q=値", "http://xn--r8jz45g.jp/パス?q=値"},
+		{"userinfo", "https://user:pa@ss@例え.jp/", "https://user:pa@ss@xn--r8jz45g.jp/"},
+		{"port", "http://例え.jp:8080/admin", "http://xn--r8jz45g.jp:8080/admin"},
+		{"userinfo and port", "http://u:p@bücher.de:81", "http://u:p@xn--bcher-kva.de:81"},
+		{"ipv6 literal", "http://[::1]:8080/例", "http://[::1]:8080/例"},
+		{"ipv6 literal without port", "http://[fe80::1]/", "http://[fe80::1]/"},
+		{"ascii host", "http://example.com:8080/例え", "http://example.com:8080/例え"},
+		{"conversion fails", "http://例え\u0000.jp/", "http://例え\u0000.jp/"},
+		{"no scheme", "例え.jp/admin", "例え.jp/admin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := asciiURL(tt.in); got != tt.want {
+				t.Errorf("asciiURL(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
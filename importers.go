@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// targetImporter extracts the URL found on one line of another scanner's
+// output, reporting false for lines that carry none (banners, progress,
+// errors).
+type targetImporter func(line string) (string, bool)
+
+// targetImporters are the -input-format values besides plain and json.
+var targetImporters = map[string]targetImporter{
+	"gobuster":    gobusterLine,
+	"dirb":        dirbLine,
+	"feroxbuster": feroxbusterLine,
+}
+
+var (
+	// gobuster dir -o: "/admin (Status: 301) [Size: 178] [--> ...]", with
+	// full URLs under -e.
+	gobusterRe = regexp.MustCompile(`^(\S+)\s+\(Status:\s*\d{3}\)`)
+	// dirb: "+ http://host/admin (CODE:200|SIZE:123)" and
+	// "==> DIRECTORY: http://host/admin/".
+	dirbRe = regexp.MustCompile(`^(?:\+\s+(\S+)\s+\(CODE:\d{3}|==> DIRECTORY:\s+(\S+))`)
+	// feroxbuster text: "200      GET       10l       20w      300c http://host/admin".
+	feroxRe = regexp.MustCompile(`^\d{3}\s+[A-Z]+\s+.*\s(https?://\S+)`)
+)
+
+func gobusterLine(line string) (string, bool) {
+	m := gobusterRe.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+	if strings.Contains(m[1], "://") {
+		return m[1], true
+	}
+	// Plain gobuster output only has the path; it is relative to -u.
+	if *url == "" || !strings.HasPrefix(m[1], "/") {
+		return "", false
+	}
+	return strings.TrimRight(*url, "/") + m[1], true
+}
+
+func dirbLine(line string) (string, bool) {
+	m := dirbRe.FindStringSubmatch(line)
+	if m == nil {
+		return "", false
+	}
+	if m[1] != "" {
+		return m[1], true
+	}
+	return m[2], true
+}
+
+// feroxbusterLine reads both the text output and --json lines, of which
+// only "response" records name a found URL.
+func feroxbusterLine(line string) (string, bool) {
+	if strings.HasPrefix(line, "{") {
+		var rec struct {
+			Type string `json:"type"`
+			URL  string `json:"url"`
+		}
+		if json.Unmarshal([]byte(line), &rec) != nil || rec.Type != "response" || rec.URL == "" {
+			return "", false
+		}
+		return rec.URL, true
+	}
+	if m := feroxRe.FindStringSubmatch(line); m != nil {
+		return m[1], true
+	}
+	return "", false
+}
+
+// checkInputFormat validates -input-format.
+func checkInputFormat(format string) error {
+	switch format {
+	case "", "plain", "json":
+		return nil
+	}
+	if _, ok := targetImporters[format]; !ok {
+		return fmt.Errorf("%q: use plain, json, gobuster, dirb or feroxbuster", format)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// passResults collects the results of the running -interval pass; the next
+// pass diffs against them like against a -baseline.
+var (
+	passResultsMu sync.Mutex
+	passResults   map[string]baselineEntry
+)
+
+func recordPassResult(r Result) {
+	passResultsMu.Lock()
+	passResults[r.URL] = baselineEntry{Status: r.Status, Length: r.Length, ETag: r.ETag, LastModified: r.LastModified}
+	passResultsMu.Unlock()
+}
+
+// startPass prints the header of pass n. From the second pass on the
+// previous pass becomes the baseline and the per-pass counters start over.
+func startPass(n int) {
+	passResultsMu.Lock()
+	if n > 1 {
+		baseline = passResults
+	}
+	passResults = make(map[string]baselineEntry)
+	passResultsMu.Unlock()
+
+	if n > 1 {
+		resetPassCounters()
+	}
+	fmt.Println(blue(fmt.Sprintf("[interval] pass %d at %s", n, time.Now().Format(time.RFC3339))))
+}
+
+func resetPassCounters() {
+	errorCountsMu.Lock()
+	errorCounts = make(map[string]int)
+	errorCountsMu.Unlock()
+
+	kindCountsMu.Lock()
+	kindCounts = make(map[string]int)
+	kindCountsMu.Unlock()
+
+	statusClassesMu.Lock()
+	statusClasses = [6]int{}
+	statusClassesMu.Unlock()
+
+	serverErrorsMu.Lock()
+	serverErrors = make(map[int]int)
+	serverErrorsMu.Unlock()
+
+	requestsSent.Store(0)
+	rateLimited.Store(0)
+
+	if responses != nil {
+		responses.mu.Lock()
+		responses.rows, responses.dropped = nil, 0
+		responses.mu.Unlock()
+	}
+
+	requestsTaken.Store(0)
+	scanLimited.Store(false)
+	hitCounts.Range(func(k, _ any) bool {
+		hitCounts.Delete(k)
+		return true
+	})
+	statusCounts.Range(func(k, _ any) bool {
+		statusCounts.Delete(k)
+		return true
+	})
+	sectionsDone.Range(func(k, _ any) bool {
+		sectionsDone.Delete(k)
+		return true
+	})
+}
+
+// waitInterval sleeps until the next pass is due, reporting false if ctx is
+// cancelled first.
+func waitInterval(ctx context.Context) bool {
+	fmt.Println(blue(fmt.Sprintf("[interval] next pass at %s", time.Now().Add(*interval).Format(time.RFC3339))))
+	select {
+	case <-time.After(*interval):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
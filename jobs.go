@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const defaultKeyword = "FUZZ"
+
+// job is one combination of words, Values[i] being the substitution for
+// keywords[i]. section is the -stop-on-first wordlist section, 0 for none.
+type job struct {
+	Values  []string
+	section int
+}
+
+var (
+	keywords []string
+	// keywordOrder indexes keywords longest first so FUZZ10 is replaced
+	// before FUZZ1.
+	keywordOrder []int
+)
+
+var keywordRe = regexp.MustCompile(`^[A-Z][A-Z0-9_]*$`)
+
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
+
+// parseWordlistSpec splits "path:KEYWORD"; a plain path uses FUZZ.
+func parseWordlistSpec(spec string) (path, keyword string) {
+	if i := strings.LastIndex(spec, ":"); i > 0 && keywordRe.MatchString(spec[i+1:]) {
+		return spec[:i], spec[i+1:]
+	}
+	return spec, defaultKeyword
+}
+
+// loadJobs reads every -w wordlist and combines them into jobs. Wordlists
+// sharing a keyword are concatenated.
+func loadJobs(specs []string) ([]job, error) {
+	lists := make(map[string][]string)
+	for _, spec := range specs {
+		if _, keyword := parseWordlistSpec(spec); !slices.Contains(keywords, keyword) {
+			keywords = append(keywords, keyword)
+		}
+	}
+	// Decided before reading, since it changes what a "#" line is.
+	sections := *stopOnFirst && checkStopOnFirst() && len(keywords) == 1
+	for _, spec := range specs {
+		path, keyword := parseWordlistSpec(spec)
+		words, err := readWordlist(path, sections)
+		if err != nil {
+			return nil, err
+		}
+		lists[keyword] = append(lists[keyword], words...)
+	}
+	if sections {
+		keywordOrder = []int{0}
+		return sectionJobs(lists[keywords[0]]), nil
+	}
+	if len(keywords) > 0 {
+		last := keywords[len(keywords)-1]
+		lists[last] = addExtensions(lists[last])
+	}
+	for k, list := range lists {
+		lists[k] = expandWords(list)
+	}
+
+	words := make([][]string, len(keywords))
+	for i, k := range keywords {
+		words[i] = lists[k]
+		keywordOrder = append(keywordOrder, i)
+	}
+	sort.Slice(keywordOrder, func(a, b int) bool {
+		return len(keywords[keywordOrder[a]]) > len(keywords[keywordOrder[b]])
+	})
+	if len(words) == 1 {
+		jobs := make([]job, len(words[0]))
+		for i, w := range words[0] {
+			jobs[i] = job{Values: []string{w}}
+		}
+		return jobs, nil
+	}
+
+	switch *mode {
+	case "pitchfork":
+		return pitchforkJobs(words), nil
+	default:
+		return clusterbombJobs(words), nil
+	}
+}
+
+// expandWords applies -prefix and -suffix to every word and drops the
+// duplicates that result.
+func expandWords(words []string) []string {
+	seen := make(map[string]bool, len(words))
+	expanded := make([]string, 0, len(words))
+	for _, w := range words {
+		w = *prefix + w + *suffix
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		expanded = append(expanded, w)
+	}
+	return expanded
+}
+
+// clusterbombJobs generates every combination of the wordlists.
+func clusterbombJobs(words [][]string) []job {
+	total := 1
+	for _, list := range words {
+		if len(list) == 0 {
+			return nil
+		}
+		if total > *maxCombinations/len(list) {
+			total = *maxCombinations + 1
+			break
+		}
+		total *= len(list)
+	}
+	if total > *maxCombinations {
+		fmt.Println(yellow(fmt.Sprintf("Warning: wordlist product exceeds -max-combinations %d, truncating", *maxCombinations)))
+		total = *maxCombinations
+	}
+
+	jobs := make([]job, 0, total)
+	idx := make([]int, len(words))
+	for len(jobs) < total {
+		values := make([]string, len(words))
+		for i, list := range words {
+			values[i] = list[idx[i]]
+		}
+		jobs = append(jobs, job{Values: values})
+
+		// Advance the last list fastest, like nested loops.
+		for i := len(idx) - 1; i >= 0; i-- {
+			idx[i]++
+			if idx[i] < len(words[i]) {
+				break
+			}
+			idx[i] = 0
+		}
+	}
+	return jobs
+}
+
+// pitchforkJobs pairs the wordlists line by line. Lists of unequal length
+// are cut to the shortest, with a warning naming the lengths.
+func pitchforkJobs(words [][]string) []job {
+	n := len(words[0])
+	mismatch := false
+	for _, list := range words[1:] {
+		if len(list) != n {
+			mismatch = true
+		}
+		if len(list) < n {
+			n = len(list)
+		}
+	}
+	if mismatch {
+		sizes := make([]string, len(words))
+		for i, list := range words {
+			sizes[i] = fmt.Sprintf("%s=%d", keywords[i], len(list))
+		}
+		fmt.Println(yellow(fmt.Sprintf("Warning: pitchfork wordlists differ in length (%s), using the first %d lines of each",
+			strings.Join(sizes, ", "), n)))
+	}
+	if n > *maxCombinations {
+		n = *maxCombinations
+	}
+
+	jobs := make([]job, n)
+	for j := 0; j < n; j++ {
+		values := make([]string, len(words))
+		for i, list := range words {
+			values[i] = list[j]
+		}
+		jobs[j] = job{Values: values}
+	}
+	return jobs
+}
+
+func (j job) key() string {
+	return strings.Join(j.Values, "\x00")
+}
+
+// URL substitutes the job's words into base. A base without any keyword
+// gets the words appended as a path, as with a plain wordlist.
+func (j job) URL(base string) string {
+	found := false
+	for _, i := range keywordOrder {
+		if strings.Contains(base, keywords[i]) {
+			base = strings.ReplaceAll(base, keywords[i], j.Values[i])
+			found = true
+		}
+	}
+	if found {
+		return base
+	}
+	return formatURL(base, strings.Join(j.Values, "/"))
+}
+
+func (j job) mapValues(f func(string) string) job {
+	values := make([]string, len(j.Values))
+	for i, v := range j.Values {
+		values[i] = f(v)
+	}
+	return job{Values: values, section: j.section}
+}
+
+// probeURL builds the URL for a single probe path such as a random
+// calibration word, placing it wherever the wordlist words would go.
+func probeURL(base, path string) string {
+	found := false
+	for _, i := range keywordOrder {
+		if strings.Contains(base, keywords[i]) {
+			base = strings.ReplaceAll(base, keywords[i], strings.TrimLeft(path, "/"))
+			found = true
+		}
+	}
+	if found {
+		return base
+	}
+	return formatURL(base, path)
+}
+
+// checkKeywords warns about targets that lack some of the wordlist keywords.
+func checkKeywords(urls []Target) {
+	if len(keywords) < 2 {
+		return
+	}
+	for _, t := range urls {
+		for _, k := range keywords {
+			if !strings.Contains(t.URL, k) {
+				fmt.Println(yellow(fmt.Sprintf("Warning: %s does not contain keyword %s", t.URL, k)))
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// writeWordlist writes lines to a wordlist file in dir and returns its path.
+func writeWordlist(t *testing.T, dir, name, lines string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(lines), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadJobsCounts(t *testing.T) {
+	dir := t.TempDir()
+	three := writeWordlist(t, dir, "three.txt", "a\nb\nc\n")
+	two := writeWordlist(t, dir, "two.txt", "x\ny\n")
+	sections := writeWordlist(t, dir, "sections.txt", "a\n# cms\nb\nc\n# other\nd\n")
+
+	tests := []struct {
+		name        string
+		mode        string
+		stopOnFirst bool
+		maxComb     string
+		specs       []string
+		want        int
+	}{
+		{"single list", "clusterbomb", false, "1000000", []string{three}, 3},
+		{"clusterbomb", "clusterbomb", false, "1000000", []string{three + ":FUZZ", two + ":W2"}, 6},
+		{"clusterbomb capped", "clusterbomb", false, "4", []string{three + ":FUZZ", two + ":W2"}, 4},
+		{"clusterbomb shared keyword", "clusterbomb", false, "1000000", []string{three + ":FUZZ", two + ":FUZZ", two + ":W2"}, 10},
+		{"pitchfork", "pitchfork", false, "1000000", []string{three + ":FUZZ", two + ":W2"}, 2},
+		{"pitchfork equal", "pitchfork", false, "1000000", []string{three + ":FUZZ", three + ":W2"}, 3},
+		{"sections", "clusterbomb", true, "1000000", []string{sections}, 4},
+		{"sections ignored with two keywords", "clusterbomb", true, "1000000", []string{three + ":FUZZ", two + ":W2"}, 6},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setFlag(t, "mode", tt.mode)
+			setFlag(t, "stop-on-first", strconv.FormatBool(tt.stopOnFirst))
+			setFlag(t, "max-combinations", tt.maxComb)
+			keywords, keywordOrder = nil, nil
+			t.Cleanup(func() { keywords, keywordOrder = nil, nil })
+
+			jobs, err := loadJobs(tt.specs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(jobs) != tt.want {
+				t.Errorf("got %d jobs, want %d", len(jobs), tt.want)
+			}
+		})
+	}
+}
+
+// -stop-on-first is ignored with several keywords, and then "#" lines are
+// comments again rather than words to request.
+func TestStopOnFirstCommentsWithKeywords(t *testing.T) {
+	dir := t.TempDir()
+	sections := writeWordlist(t, dir, "sections.txt", "a\n# cms\nb\nc\n# other\nd\n")
+	two := writeWordlist(t, dir, "two.txt", "x\n#y\nz\n")
+	setFlag(t, "mode", "clusterbomb")
+	setFlag(t, "stop-on-first", "true")
+	keywords, keywordOrder = nil, nil
+	t.Cleanup(func() { keywords, keywordOrder = nil, nil })
+
+	jobs, err := loadJobs([]string{sections + ":FUZZ", two + ":W2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 8 {
+		t.Errorf("got %d jobs, want 8", len(jobs))
+	}
+	for _, j := range jobs {
+		for _, v := range j.Values {
+			if strings.HasPrefix(v, "#") {
+				t.Errorf("comment line %q became a word", v)
+			}
+		}
+		if j.section != 0 {
+			t.Errorf("job %v in section %d, want none", j.Values, j.section)
+		}
+	}
+}
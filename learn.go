@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	neturl "net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	learnLinkRe    = regexp.MustCompile(`(?i)(?:href|src|action)\s*=\s*["']([^"'#]+)`)
+	learnPathRe    = regexp.MustCompile(`["'](/[A-Za-z0-9_\-./~]{2,})["']`)
+	learnVarRe     = regexp.MustCompile(`\b(?:var|let|const)\s+([A-Za-z_$][\w$]{2,})`)
+	learnCommentRe = regexp.MustCompile(`(?s)<!--(.*?)-->`)
+	learnWordRe    = regexp.MustCompile(`[A-Za-z][A-Za-z0-9_\-]{2,}`)
+	learnTokenRe   = regexp.MustCompile(`^[A-Za-z0-9_.\-~]{3,40}$`)
+)
+
+// maxLearnedPerResponse caps the words taken from a single response.
+const maxLearnedPerResponse = 200
+
+// learner collects words from matched responses for -learn rounds. known
+// holds the raw words learned so far and scanned the wordlist entries
+// already requested, so nothing is requested twice.
+type learner struct {
+	mu      sync.Mutex
+	known   map[string]bool
+	scanned map[string]bool
+	next    []string
+	total   int
+}
+
+var learned *learner
+
+func newLearner(dirs []job) *learner {
+	l := &learner{known: make(map[string]bool), scanned: make(map[string]bool, len(dirs))}
+	for _, d := range dirs {
+		l.scanned[d.key()] = true
+	}
+	return l
+}
+
+// learnFrom extracts candidate words from a matched response body: path
+// segments of same-host links, JS variable names and words in HTML
+// comments.
+func (l *learner) learnFrom(pageURL string, body []byte) {
+	page, err := neturl.Parse(pageURL)
+	if err != nil {
+		return
+	}
+	text := string(body)
+
+	var words []string
+	var links []string
+	for _, m := range learnLinkRe.FindAllStringSubmatch(text, -1) {
+		links = append(links, m[1])
+	}
+	for _, m := range learnPathRe.FindAllStringSubmatch(text, -1) {
+		links = append(links, m[1])
+	}
+	for _, link := range links {
+		u, err := page.Parse(strings.TrimSpace(link))
+		if err != nil || !strings.EqualFold(u.Host, page.Host) {
+			continue
+		}
+		words = append(words, strings.Split(u.Path, "/")...)
+	}
+	for _, m := range learnVarRe.FindAllStringSubmatch(text, -1) {
+		words = append(words, m[1])
+	}
+	for _, m := range learnCommentRe.FindAllStringSubmatch(text, -1) {
+		words = append(words, learnWordRe.FindAllString(m[1], -1)...)
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	added := 0
+	for _, w := range words {
+		if added >= maxLearnedPerResponse || l.total >= *learnMax {
+			return
+		}
+		if !learnTokenRe.MatchString(w) || strings.Trim(w, "0123456789") == "" || l.known[w] {
+			continue
+		}
+		l.known[w] = true
+		l.next = append(l.next, w)
+		l.total++
+		added++
+	}
+}
+
+// nextRound returns jobs for the words learned since the last call.
+func (l *learner) nextRound() []job {
+	l.mu.Lock()
+	words := l.next
+	l.next = nil
+	l.mu.Unlock()
+
+	var jobs []job
+	for _, w := range expandWords(addExtensions(words)) {
+		j := job{Values: []string{w}}
+		if !l.scanned[j.key()] {
+			l.scanned[j.key()] = true
+			jobs = append(jobs, j)
+		}
+	}
+	return jobs
+}
+
+// checkLearn disables -learn for multi-keyword scans, where a learned word
+// has no single place to go.
+func checkLearn() bool {
+	if len(keywords) > 1 {
+		fmt.Println(yellow("Warning: -learn only works with a single wordlist keyword, ignoring it"))
+		return false
+	}
+	return true
+}
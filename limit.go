@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+var (
+	requestsTaken atomic.Int64
+	scanLimited   atomic.Bool
+)
+
+// errLimitReached is returned for a request that -limit leaves no budget
+// for.
+var errLimitReached = errors.New("request limit reached")
+
+// takeRequest claims one request from the -limit budget, reporting false
+// once it is used up. Every request draws from it, the calibration, login
+// and other probes as well as the scan; retries, redirect hops and the GET
+// after a -head-then-get HEAD count with the request they belong to.
+func takeRequest() bool {
+	if *limit <= 0 {
+		return true
+	}
+	if requestsTaken.Add(1) > int64(*limit) {
+		scanLimited.Store(true)
+		return false
+	}
+	return true
+}
+
+// limitReached reports whether the -limit budget is spent, so the job
+// feeder can stop queueing.
+func limitReached() bool {
+	return *limit > 0 && requestsTaken.Load() >= int64(*limit)
+}
+
+func printLimitSummary() {
+	if scanLimited.Load() || limitReached() {
+		fmt.Println(yellow(fmt.Sprintf("Scan limited to %d requests by -limit", *limit)))
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// logger receives operational events for -log-file: the scan lifecycle,
+// request errors and retries, and per-host decisions such as throttling or
+// auto-filtering. Results never go to it. Without -log-file it discards
+// everything below a level nothing logs at, so calls cost next to nothing.
+var logger = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+
+var logFile *os.File
+
+// openLog points logger at -log-file, keeping records at -log-level and
+// above.
+func openLog(path, level string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.ToLower(level))); err != nil {
+		return err
+	}
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	logFile = f
+	logger = slog.New(slog.NewTextHandler(f, &slog.HandlerOptions{Level: lvl}))
+	return nil
+}
+
+func closeLog() {
+	if logFile != nil {
+		logFile.Close()
+	}
+}
+
+// pathOf returns the path and query of rawURL for log records, which carry
+// the host separately.
+func pathOf(rawURL string) string {
+	if i := strings.Index(rawURL, "://"); i >= 0 {
+		rest := rawURL[i+3:]
+		if j := strings.IndexByte(rest, '/'); j >= 0 {
+			return rest[j:]
+		}
+		return "/"
+	}
+	return rawURL
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/valyala/fasthttp"
+)
+
+// loginSession holds the cookie jar obtained by posting -login-data to
+// -login-url and shared by every request of the scan.
+type loginSession struct {
+	url  string
+	data string
+	path string
+
+	mu         sync.RWMutex
+	cookies    map[string]string
+	generation int
+	refreshMu  sync.Mutex
+}
+
+var session *loginSession
+
+func newLoginSession(loginURL, data string) *loginSession {
+	s := &loginSession{
+		url:     loginURL,
+		data:    data,
+		cookies: make(map[string]string),
+	}
+	uri := fasthttp.AcquireURI()
+	defer fasthttp.ReleaseURI(uri)
+	if err := uri.Parse(nil, []byte(loginURL)); err == nil {
+		s.path = string(uri.Path())
+	}
+	return s
+}
+
+// login posts the form and reports whether the response set any cookies.
+func (s *loginSession) login() (bool, error) {
+	client := newClient()
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	req.SetRequestURI(asciiURL(s.url))
+	req.Header.SetMethod(fasthttp.MethodPost)
+	req.Header.SetContentType("application/x-www-form-urlencoded")
+	req.SetBodyString(s.data)
+	s.apply(req)
+
+	if !takeRequest() {
+		return false, errLimitReached
+	}
+	if err := client.Do(req, resp); err != nil {
+		return false, err
+	}
+	set := s.update(resp)
+
+	if !set {
+		emitLine(yellow("Warning: login response set no cookies, login may have failed"))
+	} else if resp.StatusCode() == fasthttp.StatusOK && hasPasswordField(resp.Body()) {
+		emitLine(yellow("Warning: login response still shows a login form, credentials may be wrong"))
+	}
+	return set, nil
+}
+
+func (s *loginSession) apply(req *fasthttp.Request) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for k, v := range s.cookies {
+		req.Header.SetCookie(k, v)
+	}
+	return s.generation
+}
+
+// update stores cookies set or cleared by resp and reports whether any were set.
+func (s *loginSession) update(resp *fasthttp.Response) bool {
+	var set bool
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp.Header.VisitAllCookie(func(key, value []byte) {
+		c := fasthttp.AcquireCookie()
+		defer fasthttp.ReleaseCookie(c)
+		if err := c.ParseBytes(value); err != nil {
+			return
+		}
+		expired := c.MaxAge() < 0 || (!c.Expire().Equal(fasthttp.CookieExpireUnlimited) && c.Expire().Before(time.Now()))
+		if expired || len(c.Value()) == 0 {
+			delete(s.cookies, string(key))
+			return
+		}
+		s.cookies[string(key)] = string(c.Value())
+		set = true
+	})
+	return set
+}
+
+// expired reports whether resp bounced the request back to the login page.
+func (s *loginSession) expired(resp *fasthttp.Response) bool {
+	status := resp.StatusCode()
+	if status < 300 || status >= 400 || s.path == "" || s.path == "/" {
+		return false
+	}
+	s.mu.RLock()
+	empty := len(s.cookies) == 0
+	s.mu.RUnlock()
+	if empty {
+		return false
+	}
+	location := string(resp.Header.Peek("Location"))
+	return strings.Contains(location, s.path)
+}
+
+// refresh logs in again unless another worker already did so since the
+// request that saw the expiry was sent.
+func (s *loginSession) refresh(generation int) {
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	s.mu.RLock()
+	current := s.generation
+	s.mu.RUnlock()
+	if current != generation {
+		return
+	}
+
+	emitLine(yellow("Session appears to have expired, logging in again"))
+	set, err := s.login()
+	if err != nil {
+		emitLine(fmt.Sprint(red("Error refreshing login session: "), err))
+	}
+	s.mu.Lock()
+	if !set {
+		// Stop retrying a login that no longer works.
+		s.cookies = make(map[string]string)
+	}
+	s.generation++
+	s.mu.Unlock()
+}
+
+func hasPasswordField(body []byte) bool {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return false
+	}
+	return doc.Find(`input[type="password"]`).Length() > 0
+}
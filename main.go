@@ -1,217 +1,1578 @@
-package main
-
-import (
-	"bufio"
-	"bytes"
-	"flag"
-	"fmt"
-	"os"
-	"strings"
-	"sync"
-
-	"github.com/PuerkitoBio/goquery"
-	"github.com/fatih/color"
-	"github.com/valyala/fasthttp"
-)
-
-var (
-	url      = flag.String("u", "", "Target URL")
-	urlFile  = flag.String("U", "", "URL list file")
-	wordlist = flag.String("w", "", "Directory wordlist file")
-	threads  = flag.Int("t", 10, "Number of threads")
-	help     = flag.Bool("h", false, "Show help information")
-)
-
-var (
-	red    = color.New(color.FgRed).SprintFunc()
-	green  = color.New(color.FgGreen).SprintFunc()
-	yellow = color.New(color.FgYellow).SprintFunc()
-	blue   = color.New(color.FgBlue).SprintFunc()
-)
-
-func main() {
-	flag.Parse()
-
-	if *help || (*url == "" && *urlFile == "") || *wordlist == "" {
-		printHelp()
-		return
-	}
-
-	urls := getURLs()
-	dirs := getDirectories()
-
-	var wg sync.WaitGroup
-	jobs := make(chan string, *threads*2)
-
-	// Start workers
-	for i := 0; i < *threads; i++ {
-		go func() {
-			defer func() {
-				if r := recover(); r != nil {
-					fmt.Printf("Worker panic: %v\n", r)
-				}
-			}()
-			worker(jobs, &wg, urls)
-		}()
-	}
-
-	// Add all jobs first
-	wg.Add(len(dirs))
-
-	// Send jobs
-	go func() {
-		for _, dir := range dirs {
-			jobs <- dir
-		}
-	}()
-
-	wg.Wait()
-	close(jobs)
-}
-
-func worker(jobs <-chan string, wg *sync.WaitGroup, urls []string) {
-	client := &fasthttp.Client{
-		Name: "DirScan",
-	}
-
-	defer func() {
-		if r := recover(); r != nil {
-			fmt.Printf("Worker recovered from panic: %v\n", r)
-		}
-	}()
-
-	for dir := range jobs {
-		func() {
-			defer wg.Done()
-			for _, baseURL := range urls {
-				target := formatURL(baseURL, dir)
-				statusCode, body, err := getStatusCode(client, target)
-				if err != nil {
-					continue
-				}
-
-				title := extractTitle(body)
-				if statusCode != 404 {
-					printResult(target, statusCode, title)
-				}
-			}
-		}()
-	}
-}
-
-func formatURL(base, path string) string {
-	base = strings.TrimRight(base, "/")
-	path = strings.TrimLeft(path, "/")
-	return base + "/" + path
-}
-
-func getStatusCode(client *fasthttp.Client, url string) (int, []byte, error) {
-	req := fasthttp.AcquireRequest()
-	resp := fasthttp.AcquireResponse()
-	defer fasthttp.ReleaseRequest(req)
-	defer fasthttp.ReleaseResponse(resp)
-
-	req.SetRequestURI(url)
-	err := client.Do(req, resp)
-	if err != nil {
-		return 0, nil, err
-	}
-	body := make([]byte, len(resp.Body()))
-	copy(body, resp.Body())
-	return resp.StatusCode(), body, nil
-}
-
-func extractTitle(body []byte) string {
-	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
-	if err != nil {
-		return "N/A"
-	}
-	title := strings.TrimSpace(doc.Find("title").Text())
-	if title == "" {
-		return "No Title"
-	}
-	return title
-}
-
-func printResult(url string, status int, title string) {
-	var statusStr string
-	switch {
-	case status >= 200 && status < 300:
-		statusStr = green(fmt.Sprintf("%d", status))
-	case status >= 300 && status < 400:
-		statusStr = blue(fmt.Sprintf("%d", status))
-	case status >= 400 && status < 500:
-		statusStr = yellow(fmt.Sprintf("%d", status))
-	default:
-		statusStr = red(fmt.Sprintf("%d", status))
-	}
-
-	// 格式化输出为表格样式
-	fmt.Printf("%-40s %-10s %s\n", truncateString(url, 128), statusStr, truncateString(title, 128))
-}
-
-func truncateString(s string, maxLen int) string {
-	if len(s) > maxLen {
-		return s[:maxLen-3] + "..."
-	}
-	return s
-}
-
-func getURLs() []string {
-	var urls []string
-
-	if *url != "" {
-		urls = append(urls, *url)
-	}
-
-	if *urlFile != "" {
-		file, err := os.Open(*urlFile)
-		if err != nil {
-			fmt.Println(red("Error opening URL file:"), err)
-			os.Exit(1)
-		}
-		defer file.Close()
-
-		scanner := bufio.NewScanner(file)
-		for scanner.Scan() {
-			url := strings.TrimSpace(scanner.Text())
-			if url != "" {
-				urls = append(urls, url)
-			}
-		}
-	}
-
-	return urls
-}
-
-func getDirectories() []string {
-	var dirs []string
-
-	file, err := os.Open(*wordlist)
-	if err != nil {
-		fmt.Println(red("Error opening wordlist file:"), err)
-		os.Exit(1)
-	}
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		dir := strings.TrimSpace(scanner.Text())
-		if dir != "" {
-			dirs = append(dirs, dir)
-		}
-	}
-	return dirs
-}
-
-func printHelp() {
-	fmt.Println(strings.Repeat("-", 50))
-	fmt.Println("Directory Scanner - Fast HTTP directory brute-forcer")
-	fmt.Println(strings.Repeat("-", 50))
-	fmt.Println("Usage:")
-	flag.PrintDefaults()
-	fmt.Println("\nExamples:")
-	fmt.Println("  Scan single URL: dirscan -u http://example.com -w paths.txt")
-	fmt.Println("  Scan URL list: dirscan -U urls.txt -w paths.txt -t 20")
-	fmt.Println(strings.Repeat("-", 50))
-}
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	neturl "net/url"
+	"os"
+	"os/signal"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/fatih/color"
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	url     = flag.String("u", "", "Target URL")
+	urlFile = flag.String("U", "", "URL list file")
+	jsonIn  = flag.Bool("json-input", false, "Parse the URL list file as JSON lines ({\"url\": ..., \"headers\": {...}, \"method\": ...})")
+	threads = flag.Int("t", 10, "Number of threads")
+	shard   = flag.String("shard", "", "Only scan shard N of M of the wordlist (e.g. 2/4)")
+
+	inputFormat = flag.String("input-format", "", "Format of the -U file: plain, json (as -json-input), or another scanner's output to re-scan its finds: gobuster (paths are relative to -u), dirb, feroxbuster")
+
+	bothSchemes   = flag.Bool("both-schemes", false, "Scan targets given without a scheme over both http:// and https://")
+	defaultScheme = flag.String("default-scheme", "http", "Scheme for targets given without one, unless the port says otherwise (80/8080 http, 443/8443 https)")
+	nmapFile      = flag.String("nmap", "", "Nmap XML report (-oX) to take open HTTP/HTTPS ports from as targets")
+
+	diffVariants = flag.String("diff-variants", "", "Request every path over two variants of each target, as scheme, :port or scheme:port (e.g. http,https or :8080,:8081), and show only paths whose responses differ")
+
+	prefix = flag.String("prefix", "", "String prepended to every wordlist entry (e.g. api/)")
+	suffix = flag.String("suffix", "", "String appended to every wordlist entry (e.g. .json)")
+
+	stopOnFirst = flag.Bool("stop-on-first", false, "Treat wordlist lines starting with # as section markers and skip the rest of a section on a target once it has a match")
+
+	wordlistCache = flag.String("wordlist-cache", "", "Directory to keep -w lists downloaded from http(s):// URLs in and reuse on later runs")
+
+	extensionsFile = flag.String("X", "", "File of extensions, one per line (same forms as -x, merged with it)")
+
+	extraQuery = flag.String("query", "", `Query parameters added to every target URL, e.g. "token=abc&debug=1" (may contain FUZZ)`)
+
+	paramBatch = flag.Int("batch", 0, "Treat wordlist words as query parameter names and send this many per request (?a&b&c), narrowing batches whose response changes down to the parameter (0 = off)")
+
+	mode            = flag.String("mode", "clusterbomb", "How multiple keyword wordlists combine: clusterbomb (every combination) or pitchfork (line by line)")
+	maxCombinations = flag.Int("max-combinations", 1000000, "Maximum number of word combinations generated from multiple wordlists")
+
+	profile     = flag.String("profile", "", "Preset of defaults: quick (-t 50, -head-then-get), thorough (-recursion, -follow, -calibrate, -sensitive, -methods-probe, backup -x) or stealth (-t 2, adaptive delay, random agent, -waf-evasion); explicit flags still win")
+	randomAgent = flag.Bool("random-agent", false, "Send a random browser User-Agent with each request")
+
+	wafEvasion = flag.Bool("waf-evasion", false, "Randomize header casing, Accept headers and add benign headers per request (authorized testing only)")
+	seed       = flag.Int64("seed", 0, "Random seed for -waf-evasion (0 picks one at random)")
+
+	oobDomain  = flag.String("oob", "", "Interaction server domain; each request gets a unique token.<domain> in -oob-headers and in place of {oob} (authorized testing only)")
+	oobHeaders = flag.String("oob-headers", "Referer,X-Forwarded-Host,X-Forwarded-For,X-Real-IP,True-Client-IP,X-Wap-Profile", "Comma-separated headers that carry the -oob callback host")
+	oobLog     = flag.String("oob-log", "oob-tokens.log", "File the -oob token to request mapping is appended to")
+
+	requestID    = flag.Bool("request-id", false, "Send a unique X-Request-ID (UUID) with every request so server logs can be matched to findings")
+	requestIDLog = flag.String("request-id-log", "", "File to append each -request-id with its method and URL to")
+
+	logPath  = flag.String("log-file", "", "File to append operational logs to (lifecycle, errors, retries, per-host decisions); results are not logged")
+	logLevel = flag.String("log-level", "info", "Lowest -log-file level: debug (adds every response), info, warn or error")
+
+	tlsMin  = flag.String("tls-min", "", "Lowest TLS version to offer: tls1.0, tls1.1, tls1.2 or tls1.3 (tls1.0 reaches legacy devices)")
+	tlsMax  = flag.String("tls-max", "", "Highest TLS version to offer")
+	ciphers = flag.String("ciphers", "", "Comma-separated TLS 1.0-1.2 cipher suites by Go name, e.g. TLS_RSA_WITH_AES_128_CBC_SHA")
+
+	sni = flag.String("sni", "", "TLS server name to send instead of the URL host, e.g. to scan a CDN or shared host by IP (the Host header still follows the URL unless -json-input sets one)")
+
+	connectTimeout = flag.Duration("connect-timeout", 0, "Time allowed for the TCP connect alone, e.g. 2s to give up on dead hosts quickly (default: fasthttp's 3s)")
+	timeout        = flag.Duration("timeout", 0, "Time allowed to write a request and read its response once connected (default: no limit)")
+	unixSocket     = flag.String("unix", "", "Unix domain socket to send every request to; the URL host is only used for the Host header (e.g. -u http://localhost/)")
+
+	adaptive = flag.Bool("adaptive-delay", false, "Adjust the delay between requests to server response times and 429/503 responses")
+	minDelay = flag.Duration("min-delay", 0, "Lower bound for -adaptive-delay")
+	maxDelay = flag.Duration("max-delay", 5*time.Second, "Upper bound for -adaptive-delay")
+	help     = flag.Bool("h", false, "Show help information")
+	trace    = flag.Bool("trace", false, "Print every request with status, size, timing and redirect target to stderr")
+
+	follow       = flag.Bool("follow", false, "Follow redirects")
+	maxRedirects = flag.Int("max-redirects", 10, "Maximum redirects to follow with -follow")
+	statusChain  = flag.Bool("status-chain", false, "Show the redirect chain (e.g. 302→301→200) and final URL of followed redirects")
+
+	followSameHost = flag.Bool("follow-same-host", false, "With -follow, stop at redirects that leave the target's host and report where they pointed")
+
+	extractRedirects = flag.Bool("extract-redirects", false, "Report pages that redirect through a meta refresh or JavaScript, with their target")
+
+	headThenGet = flag.Bool("head-then-get", false, "Send HEAD first and only GET paths whose status passes the status filters (falls back to GET when HEAD is unsupported)")
+	verbose     = flag.Bool("v", false, "Verbose output")
+	quiet       = flag.Bool("q", false, "Do not show the live progress and match counter line")
+	timestamps  = flag.Bool("timestamps", false, "Prefix each result with the time it was found (RFC 3339) and add it to structured outputs")
+	apiMode     = flag.Bool("api-mode", false, "Describe JSON responses by their top-level keys instead of a title and tag API endpoints")
+	previewLen  = flag.Int("preview-len", 0, "With -v, print the first N characters of each result's visible text")
+	noHSTS      = flag.Bool("no-hsts", false, "Keep scanning http:// on hosts that send Strict-Transport-Security")
+	limit       = flag.Int("limit", 0, "Stop after sending this many requests in total, calibration, login and other probes included (0 = no limit)")
+	retries     = flag.Int("retries", 0, "Retry requests that time out or have their connection reset this many times")
+	verify      = flag.Bool("verify", false, "Request every match again after the scan and drop those that do not return the same status")
+	learn       = flag.Bool("learn", false, "Scan words found in matched responses (same-host link segments, JS variables, HTML comments) in extra rounds")
+	learnRounds = flag.Int("learn-rounds", 2, "Maximum extra rounds for -learn")
+	learnMax    = flag.Int("learn-max", 5000, "Maximum words -learn adds in total")
+	maxRuntime  = flag.Duration("max-runtime", 0, "Stop the scan after this long (e.g. 2h), flushing all output")
+
+	recursive          = flag.Bool("recursion", false, "Scan found directories with the same wordlist")
+	recursionDepth     = flag.Int("recursion-depth", 2, "Maximum directory levels -recursion descends")
+	recursionStatusStr = flag.String("recursion-status", "", "Statuses that make a result a directory to descend into, e.g. 301,200 (default: redirects to the path plus /, and 2xx/401/403 for paths ending in /)")
+
+	interval = flag.Duration("interval", 0, "Repeat the scan this often (e.g. 30m), printing only differences from the previous pass; Ctrl-C stops")
+
+	benchmark        = flag.Bool("benchmark", false, "Measure local throughput against a built-in test server and exit")
+	benchmarkThreads = flag.String("benchmark-threads", "1,5,10,25,50,100", "Thread counts to try with -benchmark")
+
+	noRecover = flag.Bool("no-recover", false, "Let worker panics crash with a full stack trace instead of being recovered (for debugging)")
+
+	splitOutput  = flag.String("split-output", "", "Directory to write a separate result file per host")
+	dbPath       = flag.String("db", "", "SQLite database file to store results in")
+	textOutput   = flag.String("o", "", "Write results as plain text to file")
+	jsonOutput   = flag.String("oJ", "", "Write results as JSON lines to file")
+	csvOutput    = flag.String("oC", "", "Write results as CSV to file")
+	sortField    = flag.String("sort", "", "Hold results until the scan ends and write them sorted by status, length, url or time (response time)")
+	sortDesc     = flag.Bool("sort-desc", false, "Reverse the -sort order")
+	grepOutput   = flag.String("og", "", `Write results as grepable URL|status|length|title|effective URL lines (|time with -timestamps) to file ('|' in a field is written as \|, '\' as \\)`)
+	mergeOutput  = flag.String("merge-output", "", "Write every format at once: BASE.txt, BASE.json, BASE.csv and BASE.grep (explicit -o/-oJ/-oC/-og win)")
+	harOutput    = flag.String("har", "", "Record every request and response in HTTP Archive (HAR) format to file")
+	execCmd      = flag.String("exec", "", "Command to run per result; {url}, {status}, {title} and {host} are substituted (values come from the target, treat as untrusted)")
+	execWorkers  = flag.Int("exec-workers", 4, "Maximum concurrent -exec commands")
+	execLog      = flag.String("exec-log", "", "File to append -exec command output to")
+	seenFile     = flag.String("seen-file", "", "File of URLs already reported; only report new ones and append them")
+	baselineDB   = flag.String("baseline", "", "Previous -db results file; only show new, changed and gone results")
+	methodsProbe = flag.Bool("methods-probe", false, "Probe allowed HTTP methods on matched paths")
+	unsafeVerbs  = flag.Bool("methods-unsafe", false, "With -methods-probe, also try PUT and DELETE on servers that send no Allow header (they may modify the target)")
+	caseProbe    = flag.Bool("case-probe", false, "Re-request matched paths in another case to infer server case sensitivity")
+	sensitive    = flag.Bool("sensitive", false, "Probe common sensitive files (.git, .env, .svn, ...) and verify their content")
+
+	preflight       = flag.Bool("preflight", false, "Send a CORS preflight (OPTIONS with Origin) to matched paths and show the Access-Control-Allow-* headers")
+	preflightOrigin = flag.String("preflight-origin", "https://attacker.example", "Origin sent with -preflight; an echo of it with credentials is flagged")
+	preflightMethod = flag.String("preflight-method", "PUT", "Access-Control-Request-Method sent with -preflight")
+
+	bloom         = flag.Bool("bloom", false, "Keep the -seen-file set in a Bloom filter: fixed memory for huge sets, at the cost of rarely skipping a new URL as seen")
+	bloomCapacity = flag.Int("bloom-capacity", 10000000, "URLs the -bloom filter is sized for; beyond it the false-positive rate climbs")
+	bloomFP       = flag.Float64("bloom-fp", 0.001, "False-positive rate of the -bloom filter at -bloom-capacity (0-1)")
+
+	validatorCmd     = flag.String("validator", "", "Command run on each candidate match, which is only reported if it exits 0; {url}, {status}, {length}, {title} and {host} are substituted (values come from the target, treat as untrusted)")
+	validatorWorkers = flag.Int("validator-workers", 4, "Maximum concurrent -validator commands")
+	validatorTimeout = flag.Duration("validator-timeout", 10*time.Second, "Time a -validator command gets before the match is rejected")
+	validatorLog     = flag.String("validator-log", "", "File to append -validator stderr and failures to")
+
+	fingerprintMode = flag.Bool("fingerprint", false, "Identify each host's web stack (CMS, framework, server) from headers, cookies and bodies; shown at the end and in JSON output")
+	autoWordlist    = flag.Bool("auto-wordlist", false, "Fingerprint each host's root first and scan it with a bundled list for its stack (iis, php, java); unrecognised hosts get -w")
+
+	bypass403 = flag.Bool("bypass-403", false, "Retry 403 responses with known bypass tricks (path variants, X-Original-URL, ...) and report those that get a 2xx")
+	bypassMax = flag.Int("bypass-max", len(bypassTechniques), "Maximum bypass attempts per 403 path with -bypass-403")
+
+	filterSimilar       = flag.Bool("filter-similar", false, "Hide responses similar to the target's soft-404 page")
+	similarityThreshold = flag.Float64("similarity-threshold", 0.9, "Similarity (0-1) at or above which a response counts as the soft-404 page")
+	lengthTolerance     = flag.Int("length-tolerance", -1, "Hide responses with the soft-404 page's status and a length within this many bytes of it, e.g. 20 for pages echoing the path (-1 = off)")
+
+	filterRoot     = flag.Bool("filter-root", false, "Hide responses identical to the host's homepage, as single-page apps serve index.html for every route")
+	rootSimilarity = flag.Float64("root-similarity", 0.9, "Similarity (0-1) at or above which -filter-root counts a response as the homepage (1 = byte-identical only)")
+
+	showHeaders = flag.String("show-headers", "", "Comma-separated response headers to display (e.g. Server,X-Powered-By,Set-Cookie)")
+
+	filterTitle      = flag.String("filter-by-title", "", "Comma-separated title substrings to hide (case-insensitive, e.g. \"404 Not Found,Access Denied\")")
+	filterTitleRegex = flag.String("filter-by-title-regex", "", "Hide results whose title matches this regular expression")
+
+	matchCodes  = flag.String("mc", "", "Comma-separated statuses to show, hiding all others (e.g. 200,301,403); replaces the default 404 hiding")
+	filterCodes = flag.String("fc", "", "Comma-separated statuses to hide (e.g. 302,401); applied after -mc, ranges and -show-404")
+	minStatus   = flag.Int("min-status", 0, "Lowest status to show (inclusive, e.g. 200)")
+	maxStatus   = flag.Int("max-status", 0, "Highest status to show (inclusive, e.g. 399)")
+	show404     = flag.Bool("show-404", false, "Show 404 responses too; without -mc or a status range nothing is hidden by status except -fc")
+
+	reportErrors = flag.Bool("report-errors", false, "Always show 5xx responses, whatever -fc and the other filters say, in their own color and with a count per status at the end")
+
+	autoFilterStatus    = flag.Bool("auto-filter-status", false, "Per host, hide a status once it makes up most responses (e.g. a host answering 403 to everything)")
+	autoFilterThreshold = flag.Float64("auto-filter-threshold", 0.9, "Share of a host's responses (0-1, after 50) at which -auto-filter-status hides a status")
+
+	postFilterExpr = flag.String("post-filter", "", "After the scan, re-filter every response, e.g. 'status==200 && length>500 || title~(?i)admin' (fields: status, length, url, host, title, type)")
+	postFilterMax  = flag.Int("post-filter-max", 1000000, "Maximum responses kept for -post-filter")
+
+	ignoreBodyOn = flag.String("ignore-body-on-status", "", "Comma-separated statuses whose body is not parsed for a title (e.g. 401,403)")
+
+	maxHitsPerHost = flag.Int("max-hits-per-host", 0, "Stop scanning a host after this many matches (0 = unlimited)")
+
+	calibrateFilters = flag.Bool("calibrate", false, "Probe each target with nonexistent paths and derive filters automatically")
+	calibrateHard    = flag.Bool("calibrate-hard", false, "Use a wider variety of calibration probes (implies -calibrate)")
+
+	ntlmAuth   = flag.String("ntlm", "", `NTLM credentials as user:pass or DOMAIN\user:pass`)
+	digestAuth = flag.String("digest", "", "HTTP Digest credentials as user:pass")
+	loginURL   = flag.String("login-url", "", "URL to POST login data to before scanning")
+	loginData  = flag.String("login-data", "", "URL-encoded login form data (e.g. user=admin&pass=secret)")
+
+	awsSign    = flag.Bool("aws-sign", false, "Sign requests with AWS SigV4 using AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN")
+	awsRegion  = flag.String("aws-region", "", "AWS region for -aws-sign (default $AWS_REGION or $AWS_DEFAULT_REGION)")
+	awsService = flag.String("aws-service", "execute-api", "AWS service name for -aws-sign (e.g. execute-api, s3)")
+)
+
+var (
+	wordlists         stringList
+	matchHeaderFlags  stringList
+	filterHeaderFlags stringList
+	ignoreRedirectTo  stringList
+	extensionFlags    stringList
+)
+
+func init() {
+	flag.Var(&wordlists, "w", "Directory wordlist file or http(s):// URL; repeat as file:KEYWORD to fuzz several keywords (e.g. -w a.txt:FUZZ1 -w b.txt:FUZZ2)")
+	flag.Var(&extensionFlags, "x", "Extensions to append to each word, e.g. php,bak; as ext:codes only those statuses are shown for it (e.g. -x php:200,403 -x bak:200)")
+	flag.Var(&matchHeaderFlags, "mh", "Only show responses with this header, as Name or Name:regex (repeatable)")
+	flag.Var(&filterHeaderFlags, "fh", "Hide responses with this header, as Name or Name:regex (repeatable)")
+	flag.Var(&ignoreRedirectTo, "ignore-redirect-to", "Drop redirects whose Location matches this regex, e.g. /login (repeatable)")
+}
+
+var ignoreBodyStatus map[int]bool
+
+var (
+	red    = color.New(color.FgRed).SprintFunc()
+	green  = color.New(color.FgGreen).SprintFunc()
+	yellow = color.New(color.FgYellow).SprintFunc()
+	blue   = color.New(color.FgBlue).SprintFunc()
+
+	changed   = color.New(color.FgMagenta, color.Bold).SprintFunc()
+	highlight = color.New(color.FgRed, color.Bold).SprintFunc()
+)
+
+func main() {
+	os.Exit(run())
+}
+
+// run is the whole program; it returns the exit code so that deferred
+// cleanup, such as flushing the output files, happens before the exit.
+func run() int {
+	flag.Parse()
+
+	if *profile != "" {
+		if err := applyProfile(*profile); err != nil {
+			fmt.Println(red("Invalid -profile value:"), err)
+			return exitFatal
+		}
+	}
+
+	if *benchmark {
+		runBenchmark()
+		return exitFound
+	}
+	if *help {
+		printHelp()
+		return exitFound
+	}
+	if (*url == "" && *urlFile == "" && *nmapFile == "") || (len(wordlists) == 0 && !*sensitive && !*autoWordlist) {
+		printHelp()
+		return exitFatal
+	}
+	if err := openLog(*logPath, *logLevel); err != nil {
+		fmt.Println(red("Invalid -log-file or -log-level value:"), err)
+		return exitFatal
+	}
+	defer closeLog()
+
+	if err := parseHeaderOptions(); err != nil {
+		fmt.Println(red("Invalid header option:"), err)
+		return exitFatal
+	}
+	if err := parseTitleFilters(); err != nil {
+		fmt.Println(red("Invalid -filter-by-title-regex value:"), err)
+		return exitFatal
+	}
+	if err := parseStatusFilters(); err != nil {
+		fmt.Println(red("Invalid status filter:"), err)
+		return exitFatal
+	}
+	var post postFilter
+	if *postFilterExpr != "" {
+		f, err := parsePostFilter(*postFilterExpr)
+		if err != nil {
+			fmt.Println(red("Invalid -post-filter value:"), err)
+			return exitFatal
+		}
+		post = f
+		responses = &responseStore{max: *postFilterMax}
+	}
+	extSpecs := extensionFlags
+	if *extensionsFile != "" {
+		specs, err := readExtensionsFile(*extensionsFile)
+		if err != nil {
+			fmt.Println(red("Error reading extensions file:"), err)
+			return exitFatal
+		}
+		extSpecs = append(extSpecs, specs...)
+	}
+	if err := parseExtensions(extSpecs); err != nil {
+		fmt.Println(red("Invalid -x value:"), err)
+		return exitFatal
+	}
+	if *recursionStatusStr != "" {
+		codes, err := parseStatusList(*recursionStatusStr)
+		if err != nil {
+			fmt.Println(red("Invalid -recursion-status value:"), err)
+			return exitFatal
+		}
+		recursionStatus = codes
+	}
+	if *sortField != "" {
+		if err := checkSort(*sortField); err != nil {
+			fmt.Println(red("Invalid -sort value:"), err)
+			return exitFatal
+		}
+		sorting = true
+	}
+	if *extraQuery != "" {
+		params, err := parseQueryParams(*extraQuery)
+		if err != nil {
+			fmt.Println(red("Invalid -query value:"), err)
+			return exitFatal
+		}
+		queryParams = params
+	}
+	if err := parseRedirectFilters(); err != nil {
+		fmt.Println(red("Invalid -ignore-redirect-to value:"), err)
+		return exitFatal
+	}
+	if *ignoreBodyOn != "" {
+		codes, err := parseStatusList(*ignoreBodyOn)
+		if err != nil {
+			fmt.Println(red("Invalid -ignore-body-on-status value:"), err)
+			return exitFatal
+		}
+		ignoreBodyStatus = codes
+	}
+	if cfg, err := parseTLSOptions(*tlsMin, *tlsMax, *ciphers, *sni); err != nil {
+		fmt.Println(red("Invalid TLS options:"), err)
+		return exitFatal
+	} else {
+		clientTLS = cfg
+	}
+	switch {
+	case *unixSocket != "":
+		clientDial = unixDialer(*unixSocket, *connectTimeout)
+	case *connectTimeout > 0:
+		clientDial = connectDialer(*connectTimeout)
+	}
+	if *bypass403 {
+		// After the TLS and dial options, which the client copies.
+		bypassClient = newBypassClient()
+	}
+	if *ntlmAuth != "" {
+		creds, err := parseNTLMCredentials(*ntlmAuth)
+		if err != nil {
+			fmt.Println(red("Invalid -ntlm value:"), err)
+			return exitFatal
+		}
+		ntlmCreds = creds
+	}
+	if *digestAuth != "" {
+		creds, err := parseDigestCredentials(*digestAuth)
+		if err != nil {
+			fmt.Println(red("Invalid -digest value:"), err)
+			return exitFatal
+		}
+		digestCreds = creds
+	}
+	if *awsSign {
+		creds, err := loadAWSCredentials(*awsRegion, *awsService)
+		if err != nil {
+			fmt.Println(red("Invalid -aws-sign setup:"), err)
+			return exitFatal
+		}
+		awsCreds = creds
+	}
+	if *loginURL != "" {
+		session = newLoginSession(*loginURL, *loginData)
+		if _, err := session.login(); err != nil {
+			fmt.Println(red("Error logging in:"), err)
+			return exitFatal
+		}
+	}
+
+	if *wafEvasion || *randomAgent {
+		initWAFEvasion(*seed)
+	}
+	if *oobDomain != "" {
+		o, err := newOOBInjector(*oobDomain, *oobHeaders, *oobLog)
+		if err != nil {
+			fmt.Println(red("Invalid -oob value:"), err)
+			return exitFatal
+		}
+		oob = o
+		defer oob.close()
+		fmt.Println(yellow("Warning: -oob injects callback hosts under " + oob.domain + " into every request; only use it against targets you are authorized to test"))
+		fmt.Println(yellow("[oob] logging tokens to " + *oobLog))
+	}
+	if *requestID && *requestIDLog != "" {
+		f, err := os.OpenFile(*requestIDLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Println(red("Error opening request ID log:"), err)
+			return exitFatal
+		}
+		requestIDFile = f
+		defer f.Close()
+	}
+	if *validatorCmd != "" {
+		v, err := newValidatorHook(*validatorCmd, *validatorWorkers, *validatorTimeout, *validatorLog)
+		if err != nil {
+			fmt.Println(red("Invalid -validator command:"), err)
+			return exitFatal
+		}
+		validator = v
+		defer v.Close()
+	}
+	if *baselineDB != "" {
+		entries, err := loadBaseline(*baselineDB)
+		if err != nil {
+			fmt.Println(red("Error loading baseline:"), err)
+			return exitFatal
+		}
+		baseline = entries
+	}
+
+	if err := checkInputFormat(*inputFormat); err != nil {
+		fmt.Println(red("Invalid -input-format value:"), err)
+		return exitFatal
+	}
+	if *defaultScheme != "http" && *defaultScheme != "https" {
+		fmt.Println(red("Invalid -default-scheme value:"), *defaultScheme)
+		return exitFatal
+	}
+
+	switch *mode {
+	case "clusterbomb", "pitchfork":
+	case "product":
+		*mode = "clusterbomb"
+	case "pairwise":
+		*mode = "pitchfork"
+	default:
+		fmt.Println(red("Invalid -mode value:"), *mode)
+		return exitFatal
+	}
+
+	if *bloom && (*bloomFP <= 0 || *bloomFP >= 1) {
+		fmt.Println(red("Invalid -bloom-fp value:"), *bloomFP)
+		return exitFatal
+	}
+	if *diffVariants != "" {
+		pair, err := parseVariants(*diffVariants)
+		if err != nil {
+			fmt.Println(red("Invalid -diff-variants value:"), err)
+			return exitFatal
+		}
+		diffPair = pair
+	}
+	if *filterRoot && (*rootSimilarity <= 0 || *rootSimilarity > 1) {
+		fmt.Println(red("Invalid -root-similarity value:"), *rootSimilarity)
+		return exitFatal
+	}
+	if *seenFile != "" {
+		s, err := openSeenStore(*seenFile)
+		if err != nil {
+			fmt.Println(red("Error opening seen file:"), err)
+			return exitFatal
+		}
+		seenURLs = s
+		defer seenURLs.Close()
+	}
+
+	urls, err := getURLs()
+	if err != nil {
+		fmt.Println(red("Error reading targets:"), err)
+		return exitFatal
+	}
+	dirs, err := loadJobs(wordlists)
+	if err != nil {
+		fmt.Println(red("Error reading wordlist:"), err)
+		return exitFatal
+	}
+	checkKeywords(urls)
+	if *shard != "" {
+		n, m, err := parseShard(*shard)
+		if err != nil {
+			fmt.Println(red("Invalid -shard value:"), err)
+			return exitFatal
+		}
+		dirs = shardJobs(dirs, n, m)
+	}
+
+	err = openOutputs()
+	defer closeOutputs()
+	if err != nil {
+		fmt.Println(red("Error opening outputs:"), err)
+		return exitFatal
+	}
+
+	if *sensitive {
+		scanSensitive(urls)
+	}
+
+	if *filterSimilar || *lengthTolerance >= 0 {
+		detectWildcards(urls)
+	}
+	if *filterRoot {
+		captureRootPages(urls)
+	}
+	if *calibrateFilters || *calibrateHard {
+		calibrate(urls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if *maxRuntime > 0 {
+		time.AfterFunc(*maxRuntime, func() {
+			fmt.Println(yellow(fmt.Sprintf("[max-runtime] %s reached, stopping", *maxRuntime)))
+			logger.Warn("max runtime reached, stopping", "max_runtime", *maxRuntime)
+			cancel()
+		})
+	}
+	// Ctrl-C stops the scan cleanly, so the outputs are complete and the exit
+	// code says it was interrupted; a second one kills the process.
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if *diffVariants != "" {
+		scanVariants(ctx, urls, dirs)
+		flushOutputs()
+		printErrorSummary()
+		return scanExitCode(ctx.Err() != nil)
+	}
+	if *paramBatch > 0 && checkParamBatch() {
+		scanParams(ctx, urls, dirs)
+		flushOutputs()
+		printErrorSummary()
+		return scanExitCode(ctx.Err() != nil)
+	}
+	useLearn := *learn && checkLearn()
+	groups := []scanGroup{{dirs: dirs, targets: urls}}
+	if *autoWordlist && checkAutoWordlist() {
+		groups = autoWordlistGroups(urls, dirs)
+	}
+	if *verify {
+		verifier = &verifyQueue{}
+	}
+	logger.Info("scan started", "targets", len(urls), "jobs", len(dirs), "threads", *threads)
+	for pass := 1; ; pass++ {
+		if *interval > 0 {
+			startPass(pass)
+		}
+		runPass(ctx, dirs, urls, groups, useLearn, post)
+		if *interval <= 0 || ctx.Err() != nil || !waitInterval(ctx) {
+			break
+		}
+	}
+	code := scanExitCode(ctx.Err() != nil)
+	logger.Info("scan finished", "interrupted", ctx.Err() != nil, "exit_code", code)
+	return code
+}
+
+// runPass scans every group once, runs the -learn and -recursion rounds with
+// the -w jobs and prints the end-of-scan summaries.
+func runPass(ctx context.Context, dirs []job, urls []Target, groups []scanGroup, useLearn bool, post postFilter) {
+	if useLearn {
+		learned = newLearner(dirs)
+	}
+	if *recursive {
+		recursion = newRecurser(urls)
+	}
+	started := time.Now()
+	stopProgress := func() {}
+	if !*quiet && stderrIsTerminal() {
+		stopProgress = startProgress()
+	}
+	for _, g := range groups {
+		if ctx.Err() != nil || limitReached() {
+			break
+		}
+		runScan(ctx, g.dirs, g.targets, *threads)
+	}
+	for round := 1; learned != nil && round <= *learnRounds && ctx.Err() == nil && !limitReached(); round++ {
+		more := learned.nextRound()
+		if len(more) == 0 {
+			break
+		}
+		fmt.Println(blue(fmt.Sprintf("[learn] round %d: %d new words from responses", round, len(more))))
+		runScan(ctx, more, urls, *threads)
+	}
+	for depth := 1; recursion != nil && depth <= *recursionDepth && ctx.Err() == nil && !limitReached(); depth++ {
+		subdirs := recursion.nextRound()
+		if len(subdirs) == 0 {
+			break
+		}
+		printRecursionRound(depth, len(subdirs))
+		runScan(ctx, dirs, subdirs, *threads)
+	}
+	if verifier != nil {
+		verifier.run(ctx, *threads)
+	}
+	stopProgress()
+	if *sortField != "" {
+		flushSorted()
+	}
+	flushOutputs()
+	printKindLegend()
+	printStatusBars()
+	printServerErrorSummary()
+	printErrorSummary()
+	printRateHint(time.Since(started))
+	logger.Info("pass finished", "elapsed", time.Since(started).Round(time.Millisecond), "requests", requestsSent.Load())
+	if *fingerprintMode {
+		printFingerprints()
+	}
+	printLimitSummary()
+	if validator != nil {
+		printValidatorSummary()
+	}
+	if post != nil {
+		printPostFiltered(post)
+	}
+}
+
+// stopGrace is how long in-flight requests get to finish once the scan has
+// been stopped.
+const stopGrace = 5 * time.Second
+
+// runScan feeds every job to a pool of workers and waits for them to finish.
+// Once ctx is cancelled no new jobs are handed out and in-flight ones get
+// stopGrace to complete.
+func runScan(ctx context.Context, dirs []job, urls []Target, threads int) {
+	progressTotal.Add(int64(len(dirs) * len(urls)))
+	var wg sync.WaitGroup
+	jobs := make(chan job, threads*2)
+
+	// Start workers. A worker that panics has already marked its job done
+	// (the job's deferred wg.Done runs while the panic unwinds) and is
+	// replaced, so the pool keeps its size.
+	var startWorker func()
+	startWorker = func() {
+		go func() {
+			if !*noRecover {
+				defer func() {
+					if r := recover(); r != nil {
+						emitLine(fmt.Sprintf("Worker panic: %v, restarting it", r))
+						logger.Error("worker panic, restarting it", "panic", r)
+						startWorker()
+					}
+				}()
+			}
+			worker(ctx, jobs, &wg, urls)
+		}()
+	}
+	for i := 0; i < threads; i++ {
+		startWorker()
+	}
+
+	// Send jobs; the sender holds its own count so Wait cannot return
+	// before it is done.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		for _, dir := range dirs {
+			if limitReached() {
+				return
+			}
+			wg.Add(1)
+			select {
+			case jobs <- dir:
+			case <-ctx.Done():
+				wg.Done()
+				return
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		select {
+		case <-done:
+		case <-time.After(stopGrace):
+			fmt.Println(yellow("Warning: in-flight requests did not finish in time, their results are dropped"))
+		}
+	}
+}
+
+func worker(ctx context.Context, jobs <-chan job, wg *sync.WaitGroup, urls []Target) {
+	client := newClient()
+
+	var pacer *adaptiveDelay
+	if *adaptive {
+		pacer = newAdaptiveDelay()
+	}
+
+	for dir := range jobs {
+		func() {
+			defer wg.Done()
+			// Each response body goes back to the pool once the next target
+			// is up or the job is done; nothing keeps it beyond that.
+			var body []byte
+			var buf *[]byte
+			defer func() { releaseBody(buf) }()
+			// With -both-schemes the http result is held until its https
+			// twin is known, so identical pairs print once.
+			var held *heldResult
+			flush := func() {
+				if held != nil {
+					held.emit(held.res)
+					held = nil
+				}
+			}
+			for _, t := range urls {
+				if ctx.Err() != nil {
+					return
+				}
+				if t.schemePair != pairSecond {
+					flush()
+				}
+				host := hostOf(t.URL)
+				if hostCapped(host) || sectionDone(t.URL, dir) {
+					continue
+				}
+				if !takeRequest() {
+					return
+				}
+				target := hstsUpgrade(dir.URL(t.URL))
+				releaseBody(buf)
+				buf, body = nil, nil
+				var statusCode int
+				var meta responseMeta
+				var err error
+				var elapsed time.Duration
+				for throttled, retried, exhausted := 0, 0, 0; ; {
+					if !waitForHost(ctx, host) {
+						err = ctx.Err()
+						break
+					}
+					if pacer != nil {
+						pacer.Wait()
+					}
+					start := time.Now()
+					releaseBody(buf)
+					statusCode, body, meta, err = fetchResponse(client, t, dir, target, host)
+					buf = meta.buf
+					elapsed = time.Since(start)
+					if err == nil {
+						noteResponse(statusCode)
+					}
+					if pacer != nil {
+						pacer.Observe(statusCode, elapsed)
+					}
+					if err != nil {
+						if errors.Is(err, fasthttp.ErrNoFreeConns) && exhausted < poolRetries {
+							exhausted++
+							logger.Debug("connection pool exhausted, backing off", "host", host, "path", pathOf(target), "attempt", exhausted)
+							poolBackoff(exhausted)
+							continue
+						}
+						class := recordError(err)
+						if transientError(class) && retried < *retries {
+							retried++
+							logger.Info("retrying request", "host", host, "path", pathOf(target), "attempt", retried, "error", class)
+							continue
+						}
+						logger.Warn("request failed", "host", host, "path", pathOf(target), "error", err)
+						break
+					}
+					if meta.RetryAfter <= 0 || throttled >= throttledRetries {
+						break
+					}
+					throttled++
+					pauseHost(host, meta.RetryAfter)
+				}
+				progressDone.Add(1)
+				if err != nil {
+					continue
+				}
+				logger.Debug("response", "host", host, "path", pathOf(target), "status", statusCode, "length", len(body), "elapsed", elapsed)
+				if responses != nil {
+					stored := storedResponse{URL: target, Host: host, Status: statusCode, Length: len(body), ContentType: meta.ContentType}
+					if !ignoreBodyStatus[statusCode] {
+						stored.Title = extractTitle(body)
+					}
+					responses.add(stored)
+				}
+
+				var tech []string
+				if *fingerprintMode {
+					tech = fingerprint(host, meta.Headers, body)
+				}
+				if *bypass403 && statusCode == fasthttp.StatusForbidden {
+					probeBypass(t, target, host)
+				}
+
+				autoHidden := *autoFilterStatus && observeStatus(host, statusCode)
+				surfaced := surfaceServerError(statusCode)
+				if !surfaced && (autoHidden || isFiltered(t, dir, statusCode, body) || headersFiltered(meta.Headers) || redirectFiltered(meta)) {
+					if baseline != nil {
+						reportGone(target)
+					}
+					continue
+				}
+
+				var title, preview string
+				if !ignoreBodyStatus[statusCode] {
+					n := 0
+					if *verbose {
+						n = *previewLen
+					}
+					title, preview = extractPage(body, n)
+				}
+				var tags []string
+				if *apiMode && !ignoreBodyStatus[statusCode] {
+					if apiT, ok := apiTitle(meta.ContentType, body); ok {
+						title = apiT
+						tags = append(tags, "api")
+					}
+				}
+				if !surfaced && titleFiltered(title) {
+					if baseline != nil {
+						reportGone(target)
+					}
+					continue
+				}
+				page := target
+				if len(meta.Redirects) > 0 {
+					page = meta.Redirects[len(meta.Redirects)-1].URL
+				}
+				if learned != nil {
+					learned.learnFrom(page, body)
+				}
+				var soft, softKind string
+				if *extractRedirects && !ignoreBodyStatus[statusCode] {
+					soft, softKind = softRedirect(page, body)
+				}
+				res := Result{
+					URL:    target,
+					Host:   host,
+					Status: statusCode,
+					Length: len(body),
+					Title:  title,
+					Tags:   tags,
+
+					EffectiveURL: meta.EffectiveURL,
+					ETag:         meta.ETag,
+					LastModified: meta.LastModified,
+					ContentType:  meta.ContentType,
+					Headers:      shownHeaders(meta.Headers),
+					Redirects:    meta.Redirects,
+					Via:          meta.Via,
+					Preview:      preview,
+
+					SoftRedirect:     soft,
+					SoftRedirectKind: softKind,
+					OffHost:          meta.OffHost,
+
+					Tech:    tech,
+					elapsed: elapsed,
+				}
+				emit := func(res Result) {
+					if validator != nil && !validator.accept(res) {
+						return
+					}
+					if !claimSection(t.URL, dir) {
+						return
+					}
+					if *maxHitsPerHost > 0 && !claimHit(host) {
+						return
+					}
+					if *methodsProbe {
+						res.Methods = probeMethods(client, t, target)
+					}
+					if *preflight {
+						res.CORS = probePreflight(client, t, target)
+						res.Tags = append(res.Tags, corsTags(res.CORS)...)
+					}
+					if verifier != nil {
+						verifier.hold(t, res)
+					} else {
+						writeResult(res)
+					}
+					if recursion != nil && !dir.noFollow() {
+						recursion.consider(t, res, meta.Location)
+					}
+					if *caseProbe {
+						probeCase(client, t, dir, res.Status, res.Length)
+					}
+				}
+				if t.schemePair == pairFirst {
+					held = &heldResult{res: res, emit: emit}
+					continue
+				}
+				if held != nil && t.schemePair == pairSecond && sameAcrossSchemes(held.res, res) {
+					res.Schemes = "http+https"
+					held = nil
+				}
+				flush()
+				emit(res)
+			}
+			flush()
+		}()
+	}
+}
+
+func isFiltered(t Target, dir job, status int, body []byte) bool {
+	if !jobStatusAllowed(dir, status) {
+		return true
+	}
+	if *filterSimilar && isSoft404(t.URL, dir, status, body) {
+		return true
+	}
+	if *lengthTolerance >= 0 && nearWildcardLength(t.URL, status, len(body)) {
+		return true
+	}
+	if *filterRoot && isRootPage(t.URL, dir, status, body) {
+		return true
+	}
+	return isCalibrated(t.URL, status, body)
+}
+
+func isSoft404(baseURL string, dir job, status int, body []byte) bool {
+	w := getWildcard(baseURL)
+	if w == nil || w.Status != status {
+		return false
+	}
+	return similarity(w.Hash, simhash(stripReflection(body, dir.Values...))) >= *similarityThreshold
+}
+
+// nearWildcardLength reports whether a response has the soft-404 page's
+// status and a length within -length-tolerance of it, which catches pages
+// that only differ by the echoed path.
+func nearWildcardLength(baseURL string, status, length int) bool {
+	w := getWildcard(baseURL)
+	if w == nil || w.Status != status {
+		return false
+	}
+	delta := length - w.Length
+	return delta >= -*lengthTolerance && delta <= *lengthTolerance
+}
+
+func formatURL(base, path string) string {
+	base, query, fragment := splitQuery(base)
+	base = strings.TrimRight(base, "/")
+	// A '#' in a word is part of the path to request, not a fragment that
+	// would cut the word short.
+	path = strings.ReplaceAll(strings.TrimLeft(path, "/"), "#", "%23")
+	if query == "" {
+		return base + "/" + path + fragment
+	}
+	// Keep the target's query string after the appended path.
+	if strings.Contains(path, "?") {
+		return base + "/" + path + "&" + query + fragment
+	}
+	return base + "/" + path + "?" + query + fragment
+}
+
+// responseMeta carries the response headers callers need besides status and body.
+type responseMeta struct {
+	ETag         string
+	LastModified string
+	ContentType  string
+	// Location is the final response's Location header, if any.
+	Location string
+	// Headers is only collected when header display or filtering is on.
+	Headers map[string][]string
+	// buf is the pooled buffer the body was copied into, for releaseBody.
+	buf *[]byte
+	// RetryAfter is set for 429 and 503 responses carrying Retry-After.
+	RetryAfter time.Duration
+	// Via names the requests behind the result with -head-then-get.
+	Via string
+	// Redirects lists every hop ending with the final response, when -follow
+	// followed at least one redirect.
+	Redirects []redirectHop
+	// OffHost is where a redirect -follow-same-host refused to follow
+	// pointed.
+	OffHost string
+	// EffectiveURL is the URL of the final request as sent.
+	EffectiveURL string
+}
+
+type redirectHop struct {
+	Status int    `json:"status"`
+	URL    string `json:"url"`
+}
+
+func getStatusCode(client *fasthttp.Client, t Target, url string) (int, []byte, responseMeta, error) {
+	req := fasthttp.AcquireRequest()
+	resp := fasthttp.AcquireResponse()
+	defer fasthttp.ReleaseRequest(req)
+	defer fasthttp.ReleaseResponse(resp)
+
+	if oob != nil {
+		url = oob.inject(req, t, url)
+	}
+	req.SetRequestURI(asciiURL(url))
+	if t.Method != "" {
+		req.Header.SetMethod(t.Method)
+	}
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	for k, v := range t.Headers {
+		req.Header.Set(k, v)
+	}
+	if *randomAgent {
+		applyRandomAgent(req)
+	}
+	if *wafEvasion {
+		applyWAFEvasion(req)
+	}
+
+	var meta responseMeta
+	originHost := string(req.URI().Host())
+	for hop := 0; ; hop++ {
+		if *requestID {
+			setRequestID(req)
+		}
+		start := time.Now()
+		if err := send(client, req, resp); err != nil {
+			if *trace {
+				fmt.Fprintf(os.Stderr, "[trace] %s %s -> error: %v\n", req.Header.Method(), req.URI().FullURI(), err)
+			}
+			return 0, nil, responseMeta{}, err
+		}
+		if *trace {
+			traceRequest(req, resp, len(resp.Body()), time.Since(start))
+		}
+		if harLog != nil {
+			harLog.add(req, resp, start, time.Since(start))
+		}
+		noteHSTS(req.URI(), resp)
+
+		location := resp.Header.Peek("Location")
+		if !*follow || !fasthttp.StatusCodeIsRedirect(resp.StatusCode()) || len(location) == 0 || hop >= *maxRedirects {
+			break
+		}
+		if *followSameHost {
+			if meta.OffHost = offHostRedirect(req, location, originHost); meta.OffHost != "" {
+				break
+			}
+		}
+		meta.Redirects = append(meta.Redirects, redirectHop{Status: resp.StatusCode(), URL: req.URI().String()})
+		followRedirect(req, resp.StatusCode(), location)
+	}
+	if len(meta.Redirects) > 0 {
+		meta.Redirects = append(meta.Redirects, redirectHop{Status: resp.StatusCode(), URL: req.URI().String()})
+	}
+	meta.EffectiveURL = req.URI().String()
+
+	raw, err := resp.BodyUncompressed()
+	if err != nil {
+		// Unknown or corrupt encoding: match against the bytes as received.
+		raw = resp.Body()
+	}
+	meta.buf = acquireBody(len(raw))
+	body := *meta.buf
+	copy(body, raw)
+	meta.ETag = string(resp.Header.Peek("ETag"))
+	meta.LastModified = string(resp.Header.Peek("Last-Modified"))
+	meta.Location = string(resp.Header.Peek("Location"))
+	meta.ContentType = responseContentType(string(resp.Header.Peek("Content-Type")), body)
+	if needHeaders() {
+		meta.Headers = collectHeaders(resp)
+	}
+	if status := resp.StatusCode(); status == fasthttp.StatusTooManyRequests || status == fasthttp.StatusServiceUnavailable {
+		meta.RetryAfter = parseRetryAfter(string(resp.Header.Peek("Retry-After")))
+	}
+	return resp.StatusCode(), body, meta, nil
+}
+
+// send performs one request, handling authentication challenges and login
+// session expiry.
+func send(client *fasthttp.Client, req *fasthttp.Request, resp *fasthttp.Response) error {
+	var generation int
+	if session != nil {
+		generation = session.apply(req)
+	}
+	if awsCreds != nil {
+		awsCreds.sign(req, time.Now())
+	}
+
+	var err error
+	switch {
+	case ntlmCreds != nil && sendNTLMConn(req, resp):
+	case digestCreds != nil:
+		err = doDigest(client, req, resp)
+	default:
+		err = client.Do(req, resp)
+	}
+	if err != nil {
+		return err
+	}
+	if ntlmCreds != nil && ntlmChallenged(resp) {
+		if err := doNTLM(req, resp); err != nil {
+			return err
+		}
+	}
+	if session != nil {
+		session.update(resp)
+		if session.expired(resp) {
+			session.refresh(generation)
+			req.Header.DelAllCookies()
+			session.apply(req)
+			if awsCreds != nil {
+				awsCreds.sign(req, time.Now())
+			}
+			return client.Do(req, resp)
+		}
+	}
+	return nil
+}
+
+// redirectTarget resolves location against req's URI. The caller releases
+// the returned URI.
+func redirectTarget(req *fasthttp.Request, location []byte) *fasthttp.URI {
+	uri := fasthttp.AcquireURI()
+	req.URI().CopyTo(uri)
+	uri.UpdateBytes(location)
+	return uri
+}
+
+// offHostRedirect returns where location points when that is not
+// originHost; scheme and port changes on the same host are fine.
+func offHostRedirect(req *fasthttp.Request, location []byte, originHost string) string {
+	next := redirectTarget(req, location)
+	defer fasthttp.ReleaseURI(next)
+	if strings.EqualFold(hostnameOf(string(next.Host())), hostnameOf(originHost)) {
+		return ""
+	}
+	return next.String()
+}
+
+// followRedirect points req at location, resolved against the current URL.
+func followRedirect(req *fasthttp.Request, status int, location []byte) {
+	uri := redirectTarget(req, location)
+	defer fasthttp.ReleaseURI(uri)
+	req.SetRequestURI(asciiURL(string(uri.FullURI())))
+
+	if status != fasthttp.StatusTemporaryRedirect && status != fasthttp.StatusPermanentRedirect &&
+		!req.Header.IsHead() {
+		req.Header.SetMethod(fasthttp.MethodGet)
+		req.ResetBody()
+	}
+}
+
+func traceRequest(req *fasthttp.Request, resp *fasthttp.Response, size int, elapsed time.Duration) {
+	line := fmt.Sprintf("[trace] %s %s -> %d %dB %s", req.Header.Method(), req.URI().FullURI(),
+		resp.StatusCode(), size, elapsed.Round(time.Millisecond))
+	if location := resp.Header.Peek("Location"); len(location) > 0 {
+		line += " -> " + string(location)
+	}
+	fmt.Fprintln(os.Stderr, line)
+}
+
+func extractTitle(body []byte) string {
+	title, _ := extractPage(body, 0)
+	return title
+}
+
+// extractPage parses body once for its title and, when previewLen > 0, the
+// first previewLen characters of its visible text.
+func extractPage(body []byte, previewLen int) (title, preview string) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return "N/A", ""
+	}
+	title = strings.TrimSpace(doc.Find("title").Text())
+	if title == "" {
+		title = "No Title"
+	}
+	if previewLen > 0 {
+		doc.Find("script, style, noscript, title").Remove()
+		text := strings.Join(strings.Fields(visibleText(doc.Selection)), " ")
+		if r := []rune(text); len(r) > previewLen {
+			text = string(r[:previewLen]) + "..."
+		}
+		preview = text
+	}
+	return title, preview
+}
+
+func hostOf(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host
+}
+
+// printResult prints r as a table row and counts it for the end-of-scan
+// summaries.
+func printResult(r Result) {
+	countStatus(r.Status)
+	countServerError(r.Status)
+	countKind(contentKind(r.ContentType))
+	printLine(formatResultLine(r))
+}
+
+// formatResultLine renders r as a table row.
+func formatResultLine(r Result) string {
+	var statusStr string
+	switch {
+	case r.Status >= 200 && r.Status < 300:
+		statusStr = green(fmt.Sprintf("%d", r.Status))
+	case r.Status >= 300 && r.Status < 400:
+		statusStr = blue(fmt.Sprintf("%d", r.Status))
+	case r.Status >= 400 && r.Status < 500:
+		statusStr = yellow(fmt.Sprintf("%d", r.Status))
+	case *reportErrors && isServerError(r.Status):
+		statusStr = serverErrorColor(fmt.Sprintf("%d", r.Status))
+	default:
+		statusStr = red(fmt.Sprintf("%d", r.Status))
+	}
+	if r.Diff == "changed" && r.PrevStatus != r.Status {
+		statusStr = changed(fmt.Sprintf("%d→%d", r.PrevStatus, r.Status))
+	}
+
+	urlStr := truncateString(r.URL, 128)
+	if hasHighSeverityTag(r.Tags) {
+		urlStr = highlight(urlStr)
+	}
+	urlStr = padVisible(urlStr, 40)
+
+	// 格式化输出为表格样式
+	line := fmt.Sprintf("%s %s %s", urlStr, padVisible(statusStr, 10), truncateString(r.Title, 128))
+	if r.Time != "" {
+		line = r.Time + " " + line
+	}
+	if kind := kindString(contentKind(r.ContentType)); kind != "" {
+		line += " " + kind
+	}
+	if len(r.Tags) > 0 {
+		line += " " + red(tagsString(r.Tags))
+	}
+	if r.Schemes != "" {
+		line += " (" + r.Schemes + ")"
+	}
+	if r.Variant != nil {
+		line += " " + yellow(variantString(r))
+	}
+	if *statusChain && len(r.Redirects) > 0 {
+		line += " " + blue(chainString(r.Redirects))
+	}
+	if r.SoftRedirect != "" {
+		line += " " + blue(softRedirectString(r))
+	}
+	if r.OffHost != "" {
+		line += " " + yellow("[off-host → "+r.OffHost+"]")
+	}
+	if len(r.Headers) > 0 {
+		line += " " + headersString(r.Headers)
+	}
+	if r.Diff != "" {
+		line += " " + diffMarker(r)
+	}
+	if *verbose && r.Via != "" {
+		line += " via " + r.Via
+	}
+	if r.CORS != nil {
+		if hasHighSeverityTag(r.Tags) {
+			line += " " + red(corsString(r.CORS))
+		} else {
+			line += " " + corsString(r.CORS)
+		}
+	}
+	if len(r.Methods) > 0 {
+		if hasDangerousMethod(r.Methods) {
+			line += " " + red(methodsString(r.Methods))
+		} else {
+			line += " " + methodsString(r.Methods)
+		}
+	}
+	if r.Preview != "" {
+		line += "\n    " + r.Preview
+	}
+	return line
+}
+
+var ansiRe = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// padVisible pads s with spaces to width columns, ignoring ANSI color codes
+// that %-Ns would count as characters.
+func padVisible(s string, width int) string {
+	n := utf8.RuneCountInString(ansiRe.ReplaceAllString(s, ""))
+	if n >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// visibleText joins the text nodes under s with spaces, so adjacent
+// elements such as links do not run together.
+func visibleText(s *goquery.Selection) string {
+	var b strings.Builder
+	s.Contents().Each(func(_ int, c *goquery.Selection) {
+		if goquery.NodeName(c) == "#text" {
+			b.WriteString(c.Text())
+			b.WriteByte(' ')
+		} else {
+			b.WriteString(visibleText(c))
+		}
+	})
+	return b.String()
+}
+
+func truncateString(s string, maxLen int) string {
+	if len(s) > maxLen {
+		return s[:maxLen-3] + "..."
+	}
+	return s
+}
+
+// Target is a base URL with optional per-target request settings, as
+// loaded from a JSON lines URL file.
+type Target struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Method  string            `json:"method"`
+
+	schemePair int
+}
+
+func getURLs() ([]Target, error) {
+	var urls []Target
+
+	if *url != "" {
+		urls = append(urls, Target{URL: *url})
+	}
+
+	if *urlFile != "" {
+		file, err := openInput(*urlFile)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		isJSON := *jsonIn || *inputFormat == "json" || *inputFormat == "" && strings.HasSuffix(strings.TrimSuffix(strings.ToLower(*urlFile), ".gz"), ".jsonl")
+		importer := targetImporters[*inputFormat]
+		imported, skipped := 0, 0
+		seen := make(map[string]bool)
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			if importer != nil {
+				u, ok := importer(line)
+				if !ok {
+					skipped++
+					continue
+				}
+				if !seen[u] {
+					seen[u] = true
+					imported++
+					urls = append(urls, Target{URL: u})
+				}
+				continue
+			}
+			if !isJSON {
+				urls = append(urls, Target{URL: line})
+				continue
+			}
+
+			var t Target
+			if err := json.Unmarshal([]byte(line), &t); err != nil || t.URL == "" {
+				fmt.Println(yellow("Skipping invalid JSON target:"), line)
+				continue
+			}
+			urls = append(urls, t)
+		}
+		if importer != nil {
+			fmt.Println(blue(fmt.Sprintf("[input-format] imported %d targets from %s (%d lines skipped)", imported, *urlFile, skipped)))
+		}
+	}
+
+	if *nmapFile != "" {
+		targets, err := readNmapTargets(*nmapFile)
+		if err != nil {
+			return nil, err
+		}
+		if len(targets) == 0 {
+			fmt.Println(yellow("Warning: no open HTTP/HTTPS ports in"), *nmapFile)
+		}
+		urls = append(urls, targets...)
+	}
+
+	return addQuery(addSchemes(urls)), nil
+}
+
+// readWordlist reads one word per line. Lines of the form "word<TAB>weight"
+// are scanned highest weight first; unweighted lines count as weight 0 and
+// keep their order. With sections, "#" lines are -stop-on-first section
+// markers and kept; otherwise they are comments and skipped.
+func readWordlist(path string, sections bool) ([]string, error) {
+	var dirs []string
+	var weights []float64
+	weighted := false
+
+	remote := isRemoteWordlist(path)
+	if remote {
+		local, temp, err := fetchWordlist(path)
+		if err != nil {
+			return nil, fmt.Errorf("downloading wordlist: %w", err)
+		}
+		if temp {
+			defer os.Remove(local)
+		}
+		path = local
+	}
+
+	file, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		dir := strings.TrimSpace(scanner.Text())
+		weight := 0.0
+		if i := strings.LastIndexByte(dir, '\t'); i >= 0 {
+			if w, err := strconv.ParseFloat(strings.TrimSpace(dir[i+1:]), 64); err == nil {
+				dir, weight, weighted = strings.TrimSpace(dir[:i]), w, true
+			}
+		}
+		if isWordlistComment(dir, sections) {
+			continue
+		}
+		if dir = wordOptions(dir); dir != "" {
+			dirs = append(dirs, dir)
+			weights = append(weights, weight)
+		}
+	}
+	if remote && len(dirs) == 0 {
+		// Also drops a cached copy, so the next run downloads it again.
+		os.Remove(path)
+		return nil, errors.New("downloading wordlist: no words in the download")
+	}
+
+	if weighted {
+		order := make([]int, len(dirs))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return weights[order[a]] > weights[order[b]] })
+		sorted := make([]string, len(dirs))
+		for i, j := range order {
+			sorted[i] = dirs[j]
+		}
+		dirs = sorted
+	}
+	return dirs, nil
+}
+
+type gzipFile struct {
+	*gzip.Reader
+	file *os.File
+}
+
+func (g gzipFile) Close() error {
+	g.Reader.Close()
+	return g.file.Close()
+}
+
+// openInput opens a wordlist or URL file, transparently decompressing it
+// when it starts with the gzip magic bytes.
+func openInput(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	magic := make([]byte, 2)
+	n, _ := io.ReadFull(file, magic)
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+	if n < 2 || magic[0] != 0x1f || magic[1] != 0x8b {
+		return file, nil
+	}
+
+	zr, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return gzipFile{Reader: zr, file: file}, nil
+}
+
+func printHelp() {
+	fmt.Println(strings.Repeat("-", 50))
+	fmt.Println("Directory Scanner - Fast HTTP directory brute-forcer")
+	fmt.Println(strings.Repeat("-", 50))
+	fmt.Println("Usage:")
+	flag.PrintDefaults()
+	fmt.Println("\nStatus filtering:")
+	fmt.Println("  By default only 404 is hidden; -show-404 shows it.")
+	fmt.Println("  -mc and -min-status/-max-status replace that default with an explicit allow list.")
+	fmt.Println("  -fc is applied last and always hides its codes; -x ext:codes overrides all of these for that extension.")
+	fmt.Println("  -report-errors shows every 5xx regardless of all of the above.")
+	fmt.Println("\nWordlist syntax:")
+	fmt.Println("  One word per line; blank lines and lines starting with # are skipped (with -stop-on-first # starts a section).")
+	fmt.Println("  word<TAB>weight orders the list by weight, highest first.")
+	fmt.Println("  word !nofollow keeps -recursion out of what the word finds, e.g. \"assets !nofollow\".")
+	fmt.Println("\nExit codes:")
+	fmt.Println("  0 results found, 1 no results, 2 invalid options or I/O error, 3 stopped by Ctrl-C or -max-runtime.")
+	fmt.Println("\nExamples:")
+	fmt.Println("  Scan single URL: dirscan -u http://example.com -w paths.txt")
+	fmt.Println("  Scan URL list: dirscan -U urls.txt -w paths.txt -t 20")
+	fmt.Println("  Presets: dirscan -u http://example.com -w paths.txt -profile stealth -t 4   (quick, thorough, stealth)")
+	fmt.Println("  From Nmap: nmap -sV -oX scan.xml 10.0.0.0/24 && dirscan -nmap scan.xml -w paths.txt")
+	fmt.Println("  Both schemes: dirscan -U hosts.txt -w paths.txt -both-schemes   (hosts listed without http:// or https://)")
+	fmt.Println("  Staging vs prod ports: dirscan -u http://example.com -w paths.txt -diff-variants :8080,:8081")
+	fmt.Println("  Two keywords: dirscan -u http://example.com/FUZZ1/FUZZ2 -w dirs.txt:FUZZ1 -w files.txt:FUZZ2 -mode clusterbomb")
+	fmt.Println("  Paired lists: dirscan -u http://example.com/FUZZ1?id=FUZZ2 -w paths.txt:FUZZ1 -w ids.txt:FUZZ2 -mode pitchfork")
+	fmt.Println(`  Token in every request: dirscan -u http://app/ -w paths.txt -query "token=abc&debug=1"   (or -query "q=FUZZ" to fuzz a parameter)`)
+	fmt.Println("  Sensitive files only: dirscan -U urls.txt -sensitive")
+	fmt.Println(`  Per-result hook: dirscan -u http://example.com -w paths.txt -exec "curl -sI {url}" -exec-log hooks.log`)
+	fmt.Println(`  Custom match check: dirscan -u http://example.com -w paths.txt -validator "./check.sh {url}" -validator-log validator.log`)
+	fmt.Println("    (run without a shell; {title} and {url} are attacker-controlled, never pass them to sh -c)")
+	fmt.Println("  All formats at once: dirscan -u http://example.com -w paths.txt -merge-output scan   (scan.txt, scan.json, scan.csv, scan.grep)")
+	fmt.Println(`  Pipelines: dirscan -u http://example.com -w paths.txt -og hits.grep && awk -F"|" '$2==200 {print $1}' hits.grep`)
+	fmt.Println("  Audit trail for Burp/devtools: dirscan -u http://example.com -w paths.txt -har scan.har")
+	fmt.Println("  Form login: dirscan -u http://app -w paths.txt -login-url http://app/login -login-data 'user=a&pass=b'")
+	fmt.Println("  Digest auth: dirscan -u http://192.168.1.1 -w paths.txt -digest admin:admin")
+	fmt.Println("  Legacy device: dirscan -u https://10.0.0.5 -w paths.txt -tls-min tls1.0 -tls-max tls1.1 -ciphers TLS_RSA_WITH_AES_128_CBC_SHA")
+	fmt.Println("  CDN origin by IP: dirscan -u https://203.0.113.7 -w paths.txt -sni www.example.com")
+	fmt.Println("  Fail fast on dead hosts: dirscan -U hosts.txt -w paths.txt -connect-timeout 2s -timeout 30s")
+	fmt.Println("  Service on a socket: dirscan -unix /run/app.sock -u http://localhost/ -w paths.txt")
+	fmt.Println("  AWS SigV4: AWS_ACCESS_KEY_ID=... AWS_SECRET_ACCESS_KEY=... dirscan -u https://abc.execute-api.us-east-1.amazonaws.com/prod -w paths.txt -aws-sign -aws-region us-east-1")
+	fmt.Println("  Status range: dirscan -u http://example.com -w paths.txt -min-status 200 -max-status 399 -fc 302")
+	fmt.Println("  Backup hunting: dirscan -u http://example.com -w names.txt -x php:200,403 -x bak:200 -x old")
+	fmt.Println("  403 bypass (authorized only): dirscan -u http://example.com -w paths.txt -bypass-403 -bypass-max 5")
+	fmt.Println("  Re-filter at the end: dirscan -u http://example.com -w paths.txt -post-filter 'status==403 || length>5000 && type==json'")
+	fmt.Println("  Recycle words from responses: dirscan -u http://example.com -w paths.txt -follow -learn -learn-rounds 2")
+	fmt.Println("  Recursive: dirscan -u http://example.com -w paths.txt -recursion -recursion-depth 3 -recursion-status 301,200")
+	fmt.Println("  API discovery: dirscan -u http://api.example.com -w api-routes.txt -api-mode -mc 200,401,405")
+	fmt.Println("  CORS policies: dirscan -u http://api.example.com -w api-routes.txt -api-mode -preflight -preflight-origin https://evil.example")
+	fmt.Println("  Parameter names, 50 per request: dirscan -u http://api.example.com/search -w params.txt -batch 50")
+	fmt.Println("  Single-page app: dirscan -u https://app.example.com -w paths.txt -filter-root   (hides routes that just serve index.html)")
+	fmt.Println("  Recon: dirscan -U hosts.txt -w small.txt -fingerprint -oJ recon.json")
+	fmt.Println("  Stack-specific words: dirscan -U hosts.txt -w common.txt -auto-wordlist")
+	fmt.Println("  One hit per section (\"# name\" lines start one): dirscan -u http://example.com -w cms-probes.txt -stop-on-first")
+	fmt.Println("  Shared wordlist: dirscan -u http://example.com -w https://lists.example.com/common.txt -wordlist-cache ~/.cache/dirscan")
+	fmt.Println("  Coordinated test: dirscan -u http://example.com -w paths.txt -request-id -request-id-log ids.tsv")
+	fmt.Println("  SSRF callbacks (authorized only): dirscan -u http://example.com -w paths.txt -oob abc123.oast.example -oob-log oob.log")
+	fmt.Println("    (grep the log for the token in each DNS/HTTP interaction; put {oob} in -u or the wordlist to inject it into the URL too)")
+	fmt.Println("  Redirect chains: dirscan -u http://example.com -w paths.txt -follow -status-chain")
+	fmt.Println("  Meta refresh / JS redirects: dirscan -u http://example.com -w paths.txt -extract-redirects")
+	fmt.Println("  Weighted wordlist (word<TAB>weight, highest first): dirscan -u http://example.com -w weighted.txt")
+	fmt.Println("  Save bandwidth: dirscan -u http://example.com -w paths.txt -head-then-get -v")
+	fmt.Println("  Authenticated app: dirscan -u http://app -w paths.txt -follow -ignore-redirect-to /login -ignore-redirect-to /sso")
+	fmt.Println("  Tune -t locally: dirscan -benchmark -benchmark-threads 10,50,100")
+	fmt.Println("  Scheduled scan: dirscan -U urls.txt -w paths.txt -max-runtime 2h -db scan.sqlite")
+	fmt.Println("  Monitor for changes: dirscan -u http://example.com -w paths.txt -interval 30m   (new, changed and gone results only after the first pass)")
+	fmt.Println("  Flaky network: dirscan -u http://example.com -w paths.txt -retries 2   (only timeouts and resets are retried)")
+	fmt.Println("  Troubleshooting log: dirscan -U urls.txt -w paths.txt -log-file scan.log -log-level debug")
+	fmt.Println("  Client-ready report: dirscan -u http://example.com -w paths.txt -verify -oJ report.json   (matches that do not reproduce are dropped)")
+	fmt.Println("  Sorted report: dirscan -u http://example.com -w paths.txt -sort status -sort-desc -o report.txt")
+	fmt.Println(`  NTLM auth: dirscan -u http://intranet -w paths.txt -ntlm 'CORP\alice:secret'`)
+	fmt.Println("  Split across 4 machines: dirscan -u http://example.com -w paths.txt -shard 1/4 -db shard1.sqlite")
+	fmt.Println("    (run 1/4 .. 4/4, then merge with: cat shard*.txt | sort -u, or ATTACH the databases in sqlite3)")
+	fmt.Println(strings.Repeat("-", 50))
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/valyala/fasthttp"
+)
+
+// Wordlists are read the same whether gzipped or plain, with or without a
+// .gz suffix.
+func TestReadWordlistGzip(t *testing.T) {
+	const words = "admin\nlogin\n\nbackup\n"
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(words))
+	zw.Close()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"plain.txt", []byte(words)},
+		{"gzipped.txt.gz", gz.Bytes()},
+		{"gzipped-no-suffix.txt", gz.Bytes()},
+	}
+	dir := t.TempDir()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := readWordlist(path, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := []string{"admin", "login", "backup"}; !reflect.DeepEqual(got, want) {
+				t.Errorf("got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+// The status and title columns line up whether or not the status is
+// colored; widths are measured with the ANSI codes stripped.
+func TestPrintResultAlignment(t *testing.T) {
+	results := []Result{
+		{URL: "http://example.com/a", Status: 200, Title: "ok"},
+		{URL: "http://example.com/redirect", Status: 301, Title: "moved"},
+		{URL: "http://example.com/forbidden/path", Status: 403, Title: "no"},
+		{URL: "http://example.com/x", Status: 500, Title: "error"},
+		{URL: "http://example.com/dead", Status: 404, Title: "gone", Diff: "changed", PrevStatus: 200},
+	}
+	for _, noColor := range []bool{true, false} {
+		t.Run("nocolor="+strconv.FormatBool(noColor), func(t *testing.T) {
+			old := color.NoColor
+			color.NoColor = noColor
+			t.Cleanup(func() { color.NoColor = old })
+
+			out := captureStdout(t, func() {
+				for _, r := range results {
+					printResult(r)
+				}
+			})
+			if hasANSI := ansiRe.MatchString(out); hasANSI == noColor {
+				t.Fatalf("ANSI codes in output: %v, want %v", hasANSI, !noColor)
+			}
+			lines := strings.Split(strings.TrimSuffix(ansiRe.ReplaceAllString(out, ""), "\n"), "\n")
+			if len(lines) != len(results) {
+				t.Fatalf("got %d lines, want %d", len(lines), len(results))
+			}
+			for i, line := range lines {
+				status := strings.Fields(line)[1]
+				if col := column(line, status); col != 41 {
+					t.Errorf("line %d: status %q at column %d, want 41: %q", i, status, col, line)
+				}
+				if col := column(line, results[i].Title); col != 52 {
+					t.Errorf("line %d: title at column %d, want 52: %q", i, col, line)
+				}
+			}
+		})
+	}
+}
+
+// column is the rune offset of sub in s.
+func column(s, sub string) int {
+	return utf8.RuneCountInString(s[:strings.Index(s, sub)])
+}
+
+// A brotli-encoded body is decoded before the title is taken and the
+// length measured.
+func TestBrotliBody(t *testing.T) {
+	const page = "<html><head><title>Brotli page</title></head><body>compressed</body></html>"
+	encoded := fasthttp.AppendBrotliBytes(nil, []byte(page))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "br")
+		w.Header().Set("Content-Type", "text/html")
+		w.Write(encoded)
+	}))
+	t.Cleanup(srv.Close)
+
+	status, body, meta, err := getStatusCode(newClient(), Target{URL: srv.URL}, srv.URL+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer releaseBody(meta.buf)
+	if status != 200 {
+		t.Errorf("status %d, want 200", status)
+	}
+	if len(body) != len(page) {
+		t.Errorf("body length %d, want %d", len(body), len(page))
+	}
+	if title := extractTitle(body); title != "Brotli page" {
+		t.Errorf("title %q, want %q", title, "Brotli page")
+	}
+}
+
+func TestFormatURL(t *testing.T) {
+	tests := []struct {
+		base, word, want string
+	}{
+		{"http://host", "admin", "http://host/admin"},
+		{"http://host/", "admin", "http://host/admin"},
+		{"http://host/app", "admin", "http://host/app/admin"},
+		{"http://host/app/", "admin", "http://host/app/admin"},
+		{"http://host/app/v1/api/", "users", "http://host/app/v1/api/users"},
+		{"http://host/app/v1/api", "/users", "http://host/app/v1/api/users"},
+		{"http://host/app//", "//admin", "http://host/app/admin"},
+		{"http://host/app/", "admin/config.php", "http://host/app/admin/config.php"},
+		{"http://host/app/", "admin/", "http://host/app/admin/"},
+		{"http://host/app/", "", "http://host/app/"},
+		{"http://host/app/", "a#b", "http://host/app/a%23b"},
+		{"http://host/app/?k=v", "admin", "http://host/app/admin?k=v"},
+		{"http://host/app/?k=v", "/search?q=1", "http://host/app/search?q=1&k=v"},
+		{"http://host/app/#top", "admin", "http://host/app/admin#top"},
+		{"http://host/a%20b/", "c%2Fd", "http://host/a%20b/c%2Fd"},
+	}
+	for _, tt := range tests {
+		if got := formatURL(tt.base, tt.word); got != tt.want {
+			t.Errorf("formatURL(%q, %q) = %q, want %q", tt.base, tt.word, got, tt.want)
+		}
+	}
+}
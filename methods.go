@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+var probedMethods = []string{"GET", "POST"}
+
+// unsafeMethods are only tried with -methods-unsafe: a server that accepts
+// them may really store or delete something.
+var unsafeMethods = []string{"PUT", "DELETE"}
+
+// probeMethods asks the server for its Allow header and, when none is
+// returned, falls back to trying each verb and keeping the accepted ones.
+// Refusals of any kind (400, 401, 403, 404, 405, 501) do not count as
+// accepted, so a path denied to every method reports none.
+func probeMethods(client *fasthttp.Client, t Target, url string) []string {
+	status, allow, err := sendMethod(client, t, fasthttp.MethodOptions, url)
+	if err == nil && status < 400 && allow != "" {
+		var methods []string
+		for _, m := range strings.Split(allow, ",") {
+			if m = strings.ToUpper(strings.TrimSpace(m)); m != "" {
+				methods = append(methods, m)
+			}
+		}
+		return methods
+	}
+
+	tried := probedMethods
+	if *unsafeVerbs {
+		tried = append(tried[:len(tried):len(tried)], unsafeMethods...)
+	}
+	var methods []string
+	for _, method := range tried {
+		status, _, err := sendMethod(client, t, method, url)
+		if err != nil {
+			continue
+		}
+		if !methodRefused(status) {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
+
+func methodRefused(status int) bool {
+	switch status {
+	case fasthttp.StatusBadRequest, fasthttp.StatusUnauthorized, fasthttp.StatusForbidden,
+		fasthttp.StatusNotFound, fasthttp.StatusMethodNotAllowed, fasthttp.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+func sendMethod(client *fasthttp.Client, t Target, method, url string) (int, string, error) {
+	resp, err := sendProbe(client, t, method, url, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	defer fasthttp.ReleaseResponse(resp)
+	return resp.StatusCode(), string(resp.Header.Peek("Allow")), nil
+}
+
+// sendProbe sends a method probe for url as scan requests go out: with the
+// target's headers, -random-agent, -waf-evasion and -request-id, through
+// send() for the login session and digest, NTLM or SigV4 auth, recorded in
+// -har and counted against -limit. method replaces the target's own, and
+// headers are set on top of the target's. The body is not read; the
+// caller releases the response.
+func sendProbe(client *fasthttp.Client, t Target, method, url string, headers map[string]string) (*fasthttp.Response, error) {
+	if !takeRequest() {
+		return nil, errLimitReached
+	}
+	req := fasthttp.AcquireRequest()
+	defer fasthttp.ReleaseRequest(req)
+	resp := fasthttp.AcquireResponse()
+
+	req.SetRequestURI(asciiURL(url))
+	req.Header.SetMethod(method)
+	for k, v := range t.Headers {
+		req.Header.Set(k, v)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if *randomAgent {
+		applyRandomAgent(req)
+	}
+	if *wafEvasion {
+		applyWAFEvasion(req)
+	}
+	if *requestID {
+		setRequestID(req)
+	}
+	resp.SkipBody = true
+	start := time.Now()
+	if err := send(client, req, resp); err != nil {
+		fasthttp.ReleaseResponse(resp)
+		return nil, err
+	}
+	if harLog != nil {
+		harLog.add(req, resp, start, time.Since(start))
+	}
+	return resp, nil
+}
+
+func methodsString(methods []string) string {
+	return "[" + strings.Join(methods, ",") + "]"
+}
+
+func hasDangerousMethod(methods []string) bool {
+	for _, m := range methods {
+		if m == "PUT" || m == "DELETE" {
+			return true
+		}
+	}
+	return false
+}
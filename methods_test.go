@@ -0,0 +1,48 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+// Without an Allow header only methods the server does not refuse are
+// reported, and PUT and DELETE are only sent with -methods-unsafe.
+func TestProbeMethodsFallback(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		unsafe bool
+		want   []string
+	}{
+		{"accepted", 200, false, []string{"GET", "POST"}},
+		{"accepted unsafe", 200, true, []string{"GET", "POST", "PUT", "DELETE"}},
+		{"bad request", 400, true, nil},
+		{"unauthorized", 401, true, nil},
+		{"forbidden", 403, true, nil},
+		{"not found", 404, true, nil},
+		{"redirect", 302, false, []string{"GET", "POST"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sent []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodOptions {
+					sent = append(sent, r.Method)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+			setFlag(t, "methods-unsafe", strconv.FormatBool(tt.unsafe))
+
+			if got := probeMethods(newClient(), Target{URL: srv.URL}, srv.URL+"/"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+			if !tt.unsafe && len(sent) != 2 {
+				t.Errorf("sent %v without -methods-unsafe", sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Request error classes. Only timeouts and resets are worth retrying; the
+// others will fail the same way again.
+const (
+	errDNS     = "dns"
+	errRefused = "refused"
+	errTLS     = "tls"
+	errTimeout = "timeout"
+	errReset   = "reset"
+	errPool    = "pool"
+	errOther   = "other"
+)
+
+var errorClassOrder = []string{errTimeout, errReset, errPool, errRefused, errDNS, errTLS, errOther}
+
+// poolRetries bounds how often a request that found no free connection is
+// tried again. These retries do not count against -retries: the request
+// never reached the server.
+const poolRetries = 6
+
+var (
+	errorCountsMu sync.Mutex
+	errorCounts   = make(map[string]int)
+)
+
+// classifyError sorts an error from client.Do into one of the classes above.
+func classifyError(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var recordErr tls.RecordHeaderError
+	var alertErr tls.AlertError
+	var certErr *tls.CertificateVerificationError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+
+	switch {
+	case errors.Is(err, fasthttp.ErrNoFreeConns):
+		return errPool
+	case errors.As(err, &dnsErr):
+		if dnsErr.IsTimeout || dnsErr.IsTemporary {
+			return errTimeout
+		}
+		return errDNS
+	case errors.Is(err, fasthttp.ErrTimeout), errors.Is(err, fasthttp.ErrDialTimeout),
+		errors.Is(err, os.ErrDeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return errTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return errRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE), errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF), errors.Is(err, fasthttp.ErrConnectionClosed):
+		return errReset
+	case errors.As(err, &recordErr), errors.As(err, &alertErr), errors.As(err, &certErr),
+		errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr),
+		strings.Contains(err.Error(), "tls: "):
+		return errTLS
+	}
+	return errOther
+}
+
+func transientError(class string) bool {
+	return class == errTimeout || class == errReset
+}
+
+// poolBackoff sleeps before the n-th retry of a request that found the
+// connection pool full, doubling from 50ms up to 2s.
+func poolBackoff(n int) {
+	d := 50 * time.Millisecond << (n - 1)
+	if d > 2*time.Second {
+		d = 2 * time.Second
+	}
+	time.Sleep(d)
+}
+
+// recordError counts err by class and returns the class.
+func recordError(err error) string {
+	class := classifyError(err)
+	errorCountsMu.Lock()
+	errorCounts[class]++
+	errorCountsMu.Unlock()
+	return class
+}
+
+// printErrorSummary prints how many requests failed per error class.
+func printErrorSummary() {
+	errorCountsMu.Lock()
+	defer errorCountsMu.Unlock()
+
+	var parts []string
+	for _, class := range errorClassOrder {
+		if n := errorCounts[class]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", class, n))
+		}
+	}
+	if len(parts) > 0 {
+		fmt.Println(yellow("Errors:"), strings.Join(parts, ", "))
+	}
+}
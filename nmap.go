@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+type nmapAddress struct {
+	Addr     string `xml:"addr,attr"`
+	AddrType string `xml:"addrtype,attr"`
+}
+
+type nmapHostname struct {
+	Name string `xml:"name,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type nmapRun struct {
+	Hosts []struct {
+		Addresses []nmapAddress  `xml:"address"`
+		Hostnames []nmapHostname `xml:"hostnames>hostname"`
+		Ports     []struct {
+			PortID int `xml:"portid,attr"`
+			State  struct {
+				State string `xml:"state,attr"`
+			} `xml:"state"`
+			Service struct {
+				Name   string `xml:"name,attr"`
+				Tunnel string `xml:"tunnel,attr"`
+			} `xml:"service"`
+		} `xml:"ports>port"`
+	} `xml:"host"`
+}
+
+// readNmapTargets builds base URLs for the open HTTP and HTTPS ports in an
+// Nmap XML report (nmap -oX).
+func readNmapTargets(path string) ([]Target, error) {
+	file, err := openInput(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var run nmapRun
+	if err := xml.NewDecoder(file).Decode(&run); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	var targets []Target
+	for _, h := range run.Hosts {
+		host := nmapHostName(h.Hostnames, h.Addresses)
+		if host == "" {
+			continue
+		}
+		for _, p := range h.Ports {
+			if p.State.State != "open" {
+				continue
+			}
+			scheme := nmapScheme(p.Service.Name, p.Service.Tunnel, p.PortID)
+			if scheme == "" {
+				continue
+			}
+			targets = append(targets, Target{URL: scheme + "://" + hostPort(scheme, host, p.PortID)})
+		}
+	}
+	return targets, nil
+}
+
+// nmapHostName prefers the name given on the Nmap command line, then a
+// reverse DNS name, then the IP address.
+func nmapHostName(names []nmapHostname, addrs []nmapAddress) string {
+	for _, want := range []string{"user", "PTR"} {
+		for _, n := range names {
+			if n.Type == want && n.Name != "" {
+				return n.Name
+			}
+		}
+	}
+	for _, a := range addrs {
+		if a.AddrType == "ipv4" || a.AddrType == "ipv6" {
+			return a.Addr
+		}
+	}
+	return ""
+}
+
+// nmapScheme picks http or https from service detection, falling back to
+// well-known ports when -sV was not used. Non-HTTP services return "".
+func nmapScheme(service, tunnel string, port int) string {
+	service = strings.ToLower(service)
+	switch {
+	case service == "https" || strings.HasPrefix(service, "https-") || (tunnel == "ssl" && strings.Contains(service, "http")):
+		return "https"
+	case strings.Contains(service, "http"):
+		return "http"
+	}
+	switch port {
+	case 443, 8443, 9443:
+		return "https"
+	case 80, 8000, 8008, 8080, 8888:
+		return "http"
+	}
+	return ""
+}
+
+// hostPort joins host and port, leaving out the scheme's default port.
+func hostPort(scheme, host string, port int) string {
+	if (scheme == "http" && port == 80) || (scheme == "https" && port == 443) {
+		if strings.Contains(host, ":") {
+			return "[" + host + "]"
+		}
+		return host
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf16"
+
+	"github.com/valyala/fasthttp"
+	"golang.org/x/crypto/md4"
+)
+
+const (
+	ntlmNegotiateUnicode    = 0x00000001
+	ntlmRequestTarget       = 0x00000004
+	ntlmNegotiateNTLM       = 0x00000200
+	ntlmNegotiateAlwaysSign = 0x00008000
+	ntlmNegotiateExtended   = 0x00080000
+	ntlmNegotiateTargetInfo = 0x00800000
+	ntlmNegotiate128        = 0x20000000
+	ntlmNegotiate56         = 0x80000000
+
+	ntlmFlags = ntlmNegotiateUnicode | ntlmRequestTarget | ntlmNegotiateNTLM | ntlmNegotiateAlwaysSign |
+		ntlmNegotiateExtended | ntlmNegotiateTargetInfo | ntlmNegotiate128 | ntlmNegotiate56
+
+	ntlmTimeout = 30 * time.Second
+)
+
+var ntlmSignature = []byte("NTLMSSP\x00")
+
+type ntlmCredentials struct {
+	Domain   string
+	User     string
+	Password string
+}
+
+var ntlmCreds *ntlmCredentials
+
+// parseNTLMCredentials accepts "user:pass" or "DOMAIN\user:pass".
+func parseNTLMCredentials(s string) (*ntlmCredentials, error) {
+	userPart, password, ok := strings.Cut(s, ":")
+	if !ok || userPart == "" {
+		return nil, errors.New(`expected user:pass or DOMAIN\user:pass`)
+	}
+	creds := &ntlmCredentials{User: userPart, Password: password}
+	if domain, user, ok := strings.Cut(userPart, `\`); ok {
+		creds.Domain = domain
+		creds.User = user
+	}
+	return creds, nil
+}
+
+func ntlmChallenged(resp *fasthttp.Response) bool {
+	if resp.StatusCode() != fasthttp.StatusUnauthorized {
+		return false
+	}
+	for _, v := range resp.Header.PeekAll("WWW-Authenticate") {
+		if bytes.EqualFold(bytes.TrimSpace(v), []byte("NTLM")) || bytes.HasPrefix(bytes.ToUpper(v), []byte("NTLM ")) {
+			return true
+		}
+	}
+	return false
+}
+
+// ntlmConn is a connection that completed the NTLM exchange.
+type ntlmConn struct {
+	net.Conn
+	br *bufio.Reader
+	bw *bufio.Writer
+}
+
+// ntlmIdle holds authenticated connections not in use, by scheme and
+// address. NTLM authenticates the connection rather than the request, so a
+// request sent on one of them needs no new exchange.
+var (
+	ntlmIdleMu sync.Mutex
+	ntlmIdle   = make(map[string][]*ntlmConn)
+)
+
+func takeNTLMConn(key string) *ntlmConn {
+	ntlmIdleMu.Lock()
+	defer ntlmIdleMu.Unlock()
+	conns := ntlmIdle[key]
+	if len(conns) == 0 {
+		return nil
+	}
+	c := conns[len(conns)-1]
+	ntlmIdle[key] = conns[:len(conns)-1]
+	return c
+}
+
+// putNTLMConn keeps c for the next request to key, unless resp ended the
+// connection.
+func putNTLMConn(key string, c *ntlmConn, resp *fasthttp.Response) {
+	if resp.ConnectionClose() {
+		c.Close()
+		return
+	}
+	ntlmIdleMu.Lock()
+	defer ntlmIdleMu.Unlock()
+	ntlmIdle[key] = append(ntlmIdle[key], c)
+}
+
+// sendNTLMConn sends req on an idle authenticated connection to its host and
+// reports whether resp holds the answer. A connection the server has closed
+// in the meantime is dropped and the caller sends req as usual; a 401 means
+// the server wants a new exchange, which the caller's doNTLM starts.
+func sendNTLMConn(req *fasthttp.Request, resp *fasthttp.Response) bool {
+	key, _, _ := ntlmConnKey(req)
+	c := takeNTLMConn(key)
+	if c == nil {
+		return false
+	}
+	c.SetDeadline(time.Now().Add(ntlmTimeout))
+	if err := roundTrip(req, resp, c.bw, c.br); err != nil {
+		c.Close()
+		return false
+	}
+	if resp.StatusCode() == fasthttp.StatusUnauthorized {
+		c.Close()
+	} else {
+		putNTLMConn(key, c, resp)
+	}
+	return true
+}
+
+// doNTLM replays req through the negotiate/challenge/authenticate exchange.
+// The exchange runs over a dedicated connection instead of the client pool,
+// which is kept for later requests to the host once it is authenticated.
+func doNTLM(req *fasthttp.Request, resp *fasthttp.Response) error {
+	key, addr, isTLS := ntlmConnKey(req)
+	conn, err := dialRequest(addr, isTLS)
+	if err != nil {
+		return err
+	}
+	c := &ntlmConn{Conn: conn, br: bufio.NewReader(conn), bw: bufio.NewWriter(conn)}
+	conn.SetDeadline(time.Now().Add(ntlmTimeout))
+
+	req.Header.Set("Authorization", "NTLM "+base64.StdEncoding.EncodeToString(ntlmNegotiateMessage()))
+	req.Header.Set("Connection", "keep-alive")
+	if err := roundTrip(req, resp, c.bw, c.br); err != nil {
+		conn.Close()
+		return err
+	}
+	if resp.StatusCode() != fasthttp.StatusUnauthorized {
+		conn.Close()
+		return nil
+	}
+
+	challenge, err := ntlmChallenge(resp)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	auth, err := ntlmAuthenticateMessage(challenge, ntlmCreds)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	req.Header.Set("Authorization", "NTLM "+base64.StdEncoding.EncodeToString(auth))
+	if err := roundTrip(req, resp, c.bw, c.br); err != nil {
+		conn.Close()
+		return err
+	}
+	if resp.StatusCode() == fasthttp.StatusUnauthorized {
+		conn.Close()
+	} else {
+		putNTLMConn(key, c, resp)
+	}
+	return nil
+}
+
+// ntlmConnKey returns the address req connects to and the key its
+// authenticated connections are kept under.
+func ntlmConnKey(req *fasthttp.Request) (key, addr string, isTLS bool) {
+	uri := req.URI()
+	host := string(uri.Host())
+	isTLS = bytes.Equal(uri.Scheme(), []byte("https"))
+	addr = host
+	if _, _, err := net.SplitHostPort(host); err != nil {
+		if isTLS {
+			addr = host + ":443"
+		} else {
+			addr = host + ":80"
+		}
+	}
+	if isTLS {
+		return "https://" + addr, addr, true
+	}
+	return "http://" + addr, addr, false
+}
+
+func dialRequest(addr string, isTLS bool) (net.Conn, error) {
+	var conn net.Conn
+	var err error
+	if clientDial != nil {
+		conn, err = clientDial(addr)
+	} else {
+		conn, err = fasthttp.DialTimeout(addr, ntlmTimeout)
+	}
+	if err != nil || !isTLS {
+		return conn, err
+	}
+	serverName, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	cfg := &tls.Config{ServerName: serverName}
+	if clientTLS != nil {
+		cfg = clientTLS.Clone()
+		// Keep an -sni name.
+		if cfg.ServerName == "" {
+			cfg.ServerName = serverName
+		}
+	}
+	tlsConn := tls.Client(conn, cfg)
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+func roundTrip(req *fasthttp.Request, resp *fasthttp.Response, bw *bufio.Writer, br *bufio.Reader) error {
+	if err := req.Write(bw); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	// Reset clears SkipBody. Like client.Do, skip the body of a HEAD
+	// response, or the read waits for one that never comes, and leave the
+	// caller's setting as it was.
+	skipBody := resp.SkipBody
+	resp.Reset()
+	resp.SkipBody = skipBody || req.Header.IsHead()
+	err := resp.Read(br)
+	resp.SkipBody = skipBody
+	return err
+}
+
+func ntlmChallenge(resp *fasthttp.Response) ([]byte, error) {
+	for _, v := range resp.Header.PeekAll("WWW-Authenticate") {
+		if len(v) > 5 && bytes.EqualFold(v[:5], []byte("NTLM ")) {
+			return base64.StdEncoding.DecodeString(string(bytes.TrimSpace(v[5:])))
+		}
+	}
+	return nil, errors.New("missing NTLM challenge")
+}
+
+func ntlmNegotiateMessage() []byte {
+	msg := make([]byte, 32)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 1)
+	binary.LittleEndian.PutUint32(msg[12:], ntlmFlags)
+	return msg
+}
+
+// ntlmAuthenticateMessage builds an NTLMv2 type 3 message answering the
+// server's type 2 challenge.
+func ntlmAuthenticateMessage(challenge []byte, creds *ntlmCredentials) ([]byte, error) {
+	if len(challenge) < 32 || !bytes.Equal(challenge[:8], ntlmSignature) || binary.LittleEndian.Uint32(challenge[8:]) != 2 {
+		return nil, errors.New("invalid NTLM challenge")
+	}
+	flags := binary.LittleEndian.Uint32(challenge[20:])
+	serverChallenge := challenge[24:32]
+
+	var targetInfo []byte
+	if len(challenge) >= 48 {
+		length := int(binary.LittleEndian.Uint16(challenge[40:]))
+		offset := int(binary.LittleEndian.Uint32(challenge[44:]))
+		if offset+length <= len(challenge) {
+			targetInfo = challenge[offset : offset+length]
+		}
+	}
+
+	h := md4.New()
+	h.Write(utf16le(creds.Password))
+	ntHash := h.Sum(nil)
+	ntowf := hmacMD5(ntHash, utf16le(strings.ToUpper(creds.User)+creds.Domain))
+
+	clientChallenge := make([]byte, 8)
+	rand.Read(clientChallenge)
+
+	// 1601-01-01 based timestamp in 100ns ticks
+	timestamp := make([]byte, 8)
+	binary.LittleEndian.PutUint64(timestamp, uint64(time.Now().UnixNano()/100+116444736000000000))
+
+	var blob bytes.Buffer
+	blob.Write([]byte{1, 1, 0, 0, 0, 0, 0, 0})
+	blob.Write(timestamp)
+	blob.Write(clientChallenge)
+	blob.Write([]byte{0, 0, 0, 0})
+	blob.Write(targetInfo)
+	blob.Write([]byte{0, 0, 0, 0})
+
+	ntProof := hmacMD5(ntowf, append(append([]byte{}, serverChallenge...), blob.Bytes()...))
+	ntResponse := append(ntProof, blob.Bytes()...)
+	lmResponse := append(hmacMD5(ntowf, append(append([]byte{}, serverChallenge...), clientChallenge...)), clientChallenge...)
+
+	domain := utf16le(creds.Domain)
+	user := utf16le(creds.User)
+	workstation := utf16le("DIRSCAN")
+
+	const headerLen = 64
+	msg := make([]byte, headerLen)
+	copy(msg, ntlmSignature)
+	binary.LittleEndian.PutUint32(msg[8:], 3)
+
+	offset := headerLen
+	for i, field := range [][]byte{lmResponse, ntResponse, domain, user, workstation, nil} {
+		pos := 12 + i*8
+		binary.LittleEndian.PutUint16(msg[pos:], uint16(len(field)))
+		binary.LittleEndian.PutUint16(msg[pos+2:], uint16(len(field)))
+		binary.LittleEndian.PutUint32(msg[pos+4:], uint32(offset))
+		msg = append(msg, field...)
+		offset += len(field)
+	}
+	binary.LittleEndian.PutUint32(msg[60:], flags&ntlmFlags)
+	return msg, nil
+}
+
+func hmacMD5(key, data []byte) []byte {
+	mac := hmac.New(md5.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+func utf16le(s string) []byte {
+	codes := utf16.Encode([]rune(s))
+	b := make([]byte, len(codes)*2)
+	for i, c := range codes {
+		binary.LittleEndian.PutUint16(b[i*2:], c)
+	}
+	return b
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/valyala/fasthttp"
+)
+
+// ntlmServer answers 401 NTLM until a connection completes the exchange and
+// serves every request on it afterwards, as IIS does.
+type ntlmServer struct {
+	addr     string
+	mu       sync.Mutex
+	requests int
+	authed   map[uint64]bool
+	conns    map[uint64]bool
+}
+
+func newNTLMServer(t *testing.T) *ntlmServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &ntlmServer{addr: ln.Addr().String(), authed: make(map[uint64]bool), conns: make(map[uint64]bool)}
+	challenge := make([]byte, 48)
+	copy(challenge, ntlmSignature)
+	binary.LittleEndian.PutUint32(challenge[8:], 2)
+	binary.LittleEndian.PutUint32(challenge[20:], ntlmFlags)
+	copy(challenge[24:], "8bytes!!")
+
+	srv := &fasthttp.Server{Handler: func(ctx *fasthttp.RequestCtx) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests++
+		s.conns[ctx.ConnID()] = true
+		auth := string(ctx.Request.Header.Peek("Authorization"))
+		switch {
+		case s.authed[ctx.ConnID()]:
+		case strings.HasPrefix(auth, "NTLM "):
+			msg, _ := base64.StdEncoding.DecodeString(auth[5:])
+			if len(msg) > 12 && binary.LittleEndian.Uint32(msg[8:]) == 3 {
+				s.authed[ctx.ConnID()] = true
+				break
+			}
+			ctx.Response.Header.Set("WWW-Authenticate", "NTLM "+base64.StdEncoding.EncodeToString(challenge))
+			ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+			return
+		default:
+			ctx.Response.Header.Set("WWW-Authenticate", "NTLM")
+			ctx.SetStatusCode(fasthttp.StatusUnauthorized)
+			return
+		}
+		ctx.SetContentType("text/html")
+		ctx.WriteString("<title>intranet</title>")
+	}}
+	go srv.Serve(ln)
+	t.Cleanup(func() { srv.Shutdown() })
+	return s
+}
+
+// After the first exchange later requests, HEAD included, go out on the
+// authenticated connection without a new handshake.
+func TestNTLMReusesConnection(t *testing.T) {
+	srv := newNTLMServer(t)
+	ntlmCreds = &ntlmCredentials{User: "user", Password: "pass"}
+	t.Cleanup(func() {
+		ntlmCreds = nil
+		ntlmIdleMu.Lock()
+		for _, conns := range ntlmIdle {
+			for _, c := range conns {
+				c.Close()
+			}
+		}
+		ntlmIdle = make(map[string][]*ntlmConn)
+		ntlmIdleMu.Unlock()
+	})
+
+	client := newClient()
+	base := "http://" + srv.addr + "/"
+	for i, method := range []string{"GET", "HEAD", "GET"} {
+		status, _, meta, err := getStatusCode(client, Target{URL: base, Method: method}, base+"page")
+		if err != nil {
+			t.Fatalf("request %d (%s): %v", i, method, err)
+		}
+		releaseBody(meta.buf)
+		if status != 200 {
+			t.Errorf("request %d (%s): status %d, want 200", i, method, status)
+		}
+	}
+
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	// 401, negotiate and authenticate for the first path, one each after.
+	if srv.requests != 5 {
+		t.Errorf("server got %d requests, want 5", srv.requests)
+	}
+	if len(srv.conns) != 2 {
+		t.Errorf("server saw %d connections, want 2", len(srv.conns))
+	}
+}
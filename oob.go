@@ -0,0 +1,95 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// oobPlaceholder in a target URL or wordlist entry is replaced with the
+// request's callback host.
+const oobPlaceholder = "{oob}"
+
+// oobURLHeaders take a URL rather than a bare host.
+var oobURLHeaders = map[string]bool{
+	"referer":       true,
+	"origin":        true,
+	"x-wap-profile": true,
+}
+
+// oobInjector gives every request a unique token under the user's
+// interaction server domain, puts token.domain into the configured headers
+// and logs which request got which token, so a callback seen by the server
+// can be traced back. Intended for authorized testing only.
+type oobInjector struct {
+	domain  string
+	headers []string
+
+	mu  sync.Mutex
+	log *os.File
+}
+
+var oob *oobInjector
+
+func newOOBInjector(domain, headers, logPath string) (*oobInjector, error) {
+	domain = strings.TrimPrefix(strings.TrimPrefix(domain, "http://"), "https://")
+	domain = strings.Trim(strings.ToLower(domain), "./")
+	if domain == "" || strings.ContainsAny(domain, "/:") {
+		return nil, fmt.Errorf("%q is not a domain name", domain)
+	}
+	if logPath == "" {
+		return nil, fmt.Errorf("-oob-log must not be empty")
+	}
+	o := &oobInjector{domain: domain}
+	for _, h := range strings.Split(headers, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			o.headers = append(o.headers, h)
+		}
+	}
+	f, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	o.log = f
+	return o, nil
+}
+
+// inject sets the callback headers on req and returns url with any
+// oobPlaceholder replaced by the same callback host.
+func (o *oobInjector) inject(req *fasthttp.Request, t Target, url string) string {
+	token := newOOBToken()
+	host := token + "." + o.domain
+	url = strings.ReplaceAll(url, oobPlaceholder, host)
+	for _, h := range o.headers {
+		if oobURLHeaders[strings.ToLower(h)] {
+			req.Header.Set(h, "http://"+host+"/")
+		} else {
+			req.Header.Set(h, host)
+		}
+	}
+
+	method := t.Method
+	if method == "" {
+		method = fasthttp.MethodGet
+	}
+	o.mu.Lock()
+	fmt.Fprintf(o.log, "%s\t%s\t%s\t%s\t%s\n", time.Now().Format(time.RFC3339), token, method, url, strings.Join(o.headers, ","))
+	o.mu.Unlock()
+	return url
+}
+
+func (o *oobInjector) close() {
+	o.log.Close()
+}
+
+func newOOBToken() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,402 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Result struct {
+	URL    string `json:"url"`
+	Host   string `json:"host"`
+	Status int    `json:"status"`
+	Length int    `json:"length"`
+	Title  string `json:"title"`
+
+	// EffectiveURL is the URL of the final request as sent, after path
+	// normalization and any redirects -follow took.
+	EffectiveURL string `json:"effective_url,omitempty"`
+	// Time is when the result was found, in RFC 3339, with -timestamps.
+	Time string `json:"time,omitempty"`
+
+	ETag         string            `json:"etag,omitempty"`
+	LastModified string            `json:"last_modified,omitempty"`
+	ContentType  string            `json:"content_type,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Redirects    []redirectHop     `json:"redirects,omitempty"`
+	Via          string            `json:"via,omitempty"`
+	Schemes      string            `json:"schemes,omitempty"`
+	Preview      string            `json:"preview,omitempty"`
+
+	// Variant is the second -diff-variants response for the path.
+	Variant *variantResponse `json:"variant,omitempty"`
+
+	// SoftRedirect is where a meta refresh or script sends the browser;
+	// SoftRedirectKind says which.
+	SoftRedirect     string `json:"soft_redirect,omitempty"`
+	SoftRedirectKind string `json:"soft_redirect_kind,omitempty"`
+	// OffHost is the redirect target -follow-same-host did not follow.
+	OffHost string `json:"off_host_redirect,omitempty"`
+
+	// elapsed is the response time, for -sort time.
+	elapsed time.Duration
+
+	// CORS is the -preflight answer for the path.
+	CORS *corsPolicy `json:"cors,omitempty"`
+
+	Methods []string `json:"methods,omitempty"`
+	Tech    []string `json:"tech,omitempty"`
+	Tags    []string `json:"tags"`
+
+	// Set in -baseline mode: "new", "changed" or "gone".
+	Diff       string `json:"diff,omitempty"`
+	PrevStatus int    `json:"prev_status,omitempty"`
+	PrevLength int    `json:"prev_length,omitempty"`
+}
+
+var (
+	// outputs are the sinks besides the terminal table.
+	outputs []Sink
+	// outputsMu keeps results from being queued while closeOutputs runs,
+	// e.g. from requests still in flight when -max-runtime stops the scan.
+	outputsMu     sync.RWMutex
+	outputsClosed bool
+)
+
+// openOutputs opens every output requested on the command line and starts
+// the goroutine that writes results to them. On error the outputs opened so
+// far are left in outputs for closeOutputs.
+func openOutputs() error {
+
+	if *mergeOutput != "" {
+		setUnlessGiven("o", *mergeOutput+".txt")
+		setUnlessGiven("oJ", *mergeOutput+".json")
+		setUnlessGiven("oC", *mergeOutput+".csv")
+		setUnlessGiven("og", *mergeOutput+".grep")
+	}
+	if err := checkOutputPaths(); err != nil {
+		return err
+	}
+
+	if *splitOutput != "" {
+		if err := os.MkdirAll(*splitOutput, 0755); err != nil {
+			return fmt.Errorf("-split-output: %w", err)
+		}
+		outputs = append(outputs, newHostWriters(*splitOutput))
+	}
+	if *dbPath != "" {
+		w, err := openDBWriter(*dbPath)
+		if err != nil {
+			return fmt.Errorf("-db: %w", err)
+		}
+		outputs = append(outputs, w)
+	}
+	if *textOutput != "" {
+		w, err := newTextWriter(*textOutput)
+		if err != nil {
+			return fmt.Errorf("-o: %w", err)
+		}
+		outputs = append(outputs, w)
+	}
+	if *jsonOutput != "" {
+		w, err := newJSONWriter(*jsonOutput)
+		if err != nil {
+			return fmt.Errorf("-oJ: %w", err)
+		}
+		outputs = append(outputs, w)
+	}
+	if *grepOutput != "" {
+		w, err := newGrepWriter(*grepOutput)
+		if err != nil {
+			return fmt.Errorf("-og: %w", err)
+		}
+		outputs = append(outputs, w)
+	}
+	if *csvOutput != "" {
+		w, err := newCSVWriter(*csvOutput)
+		if err != nil {
+			return fmt.Errorf("-oC: %w", err)
+		}
+		outputs = append(outputs, w)
+	}
+	if *harOutput != "" {
+		h, err := newHARWriter(*harOutput)
+		if err != nil {
+			return fmt.Errorf("-har: %w", err)
+		}
+		harLog = h
+	}
+	if *execCmd != "" {
+		h, err := newExecHook(*execCmd, *execWorkers, *execLog)
+		if err != nil {
+			return fmt.Errorf("-exec: %w", err)
+		}
+		outputs = append(outputs, h)
+	}
+	resultQueue = startSinkQueue()
+	return nil
+}
+
+// setUnlessGiven sets a flag that was not given on the command line.
+func setUnlessGiven(name, value string) {
+	given := false
+	flag.Visit(func(f *flag.Flag) { given = given || f.Name == name })
+	if !given {
+		flag.Set(name, value)
+	}
+}
+
+// checkOutputPaths rejects two outputs writing to the same file.
+func checkOutputPaths() error {
+	files := []struct{ flag, path string }{
+		{"-o", *textOutput},
+		{"-oJ", *jsonOutput},
+		{"-oC", *csvOutput},
+		{"-og", *grepOutput},
+		{"-har", *harOutput},
+		{"-db", *dbPath},
+		{"-exec-log", *execLog},
+		{"-seen-file", *seenFile},
+	}
+	if *oobDomain != "" {
+		files = append(files, struct{ flag, path string }{"-oob-log", *oobLog})
+	}
+	if *requestID {
+		files = append(files, struct{ flag, path string }{"-request-id-log", *requestIDLog})
+	}
+	if *validatorCmd != "" {
+		files = append(files, struct{ flag, path string }{"-validator-log", *validatorLog})
+	}
+	owner := make(map[string]string)
+	for _, f := range files {
+		if f.path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(f.path)
+		if err != nil {
+			abs = filepath.Clean(f.path)
+		}
+		if prev, ok := owner[abs]; ok {
+			return fmt.Errorf("%s and %s both write to %s", prev, f.flag, f.path)
+		}
+		owner[abs] = f.flag
+	}
+	return nil
+}
+
+func closeOutputs() {
+	outputsMu.Lock()
+	defer outputsMu.Unlock()
+	outputsClosed = true
+	if resultQueue != nil {
+		resultQueue.stop()
+	}
+	for _, w := range outputs {
+		w.Close()
+	}
+	if harLog != nil {
+		harLog.Close()
+	}
+}
+
+// writeResult prints r and records it in every configured output. With
+// -seen-file results reported by a previous run are skipped entirely. With
+// -baseline only differences are printed, but files still get every result
+// so the run can serve as the next baseline. -interval passes after the
+// first use the previous pass as their baseline.
+func writeResult(r Result) {
+	outputsMu.RLock()
+	defer outputsMu.RUnlock()
+	if outputsClosed {
+		return
+	}
+	if *interval > 0 {
+		recordPassResult(r)
+	}
+	if seenURLs != nil && !seenURLs.MarkNew(r.URL) {
+		return
+	}
+	if r.Tags == nil {
+		r.Tags = []string{}
+	}
+	resultsFound.Store(true)
+	if *timestamps {
+		r.Time = time.Now().Format(time.RFC3339)
+	}
+	matchCount.Add(1)
+	if holdForSort(r) {
+		return
+	}
+	emitResult(r)
+}
+
+// emitResult queues r for the terminal and the outputs. The caller holds
+// outputsMu.
+func emitResult(r Result) {
+	resultQueue.items <- sinkItem{r: r}
+}
+
+// emitTerminal queues r for the terminal table only.
+func emitTerminal(r Result) {
+	outputsMu.RLock()
+	defer outputsMu.RUnlock()
+	if !outputsClosed {
+		resultQueue.items <- sinkItem{r: r, terminalOnly: true}
+	}
+}
+
+// emitLine queues a status line printed while the scan runs, such as a
+// -max-hits or Retry-After notice, so it lands between results in the order
+// it happened. Outside a scan, and while closeOutputs runs, it prints
+// directly.
+func emitLine(line string) {
+	if !outputsMu.TryRLock() {
+		printLine(line)
+		return
+	}
+	defer outputsMu.RUnlock()
+	if resultQueue == nil || outputsClosed {
+		printLine(line)
+		return
+	}
+	resultQueue.items <- sinkItem{line: line}
+}
+
+// flushOutputs waits until every result queued so far is written, so the
+// end-of-scan summaries print after the results they count.
+func flushOutputs() {
+	outputsMu.RLock()
+	defer outputsMu.RUnlock()
+	if outputsClosed {
+		return
+	}
+	flushed := make(chan struct{})
+	resultQueue.items <- sinkItem{flushed: flushed}
+	<-flushed
+}
+
+// hostWriters keeps one output file per host, opened on the first result for that host.
+type hostWriters struct {
+	dir     string
+	files   map[string]*os.File
+	writers map[string]*bufio.Writer
+}
+
+func newHostWriters(dir string) *hostWriters {
+	return &hostWriters{
+		dir:     dir,
+		files:   make(map[string]*os.File),
+		writers: make(map[string]*bufio.Writer),
+	}
+}
+
+func (h *hostWriters) Write(r Result) {
+	w, ok := h.writers[r.Host]
+	if !ok {
+		path := filepath.Join(h.dir, sanitizeHost(r.Host)+".txt")
+		file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			fmt.Println(red("Error opening host output file:"), err)
+			return
+		}
+		w = bufio.NewWriter(file)
+		h.files[r.Host] = file
+		h.writers[r.Host] = w
+	}
+	fmt.Fprintln(w, textLine(r))
+}
+
+// textLine formats a result for the plain-text outputs, without color.
+func textLine(r Result) string {
+	line := fmt.Sprintf("%-40s %-10d %s", r.URL, r.Status, r.Title)
+	if r.Time != "" {
+		line = r.Time + " " + line
+	}
+	if len(r.Tags) > 0 {
+		line += " " + tagsString(r.Tags)
+	}
+	if len(r.Methods) > 0 {
+		line += " " + methodsString(r.Methods)
+	}
+	if r.CORS != nil {
+		line += " " + corsString(r.CORS)
+	}
+	if r.Variant != nil {
+		line += " " + variantString(r)
+	}
+	if r.SoftRedirect != "" {
+		line += " " + softRedirectString(r)
+	}
+	if r.OffHost != "" {
+		line += " [off-host → " + r.OffHost + "]"
+	}
+	if len(r.Headers) > 0 {
+		line += " " + headersString(r.Headers)
+	}
+	return line
+}
+
+func (h *hostWriters) Close() {
+	for host, w := range h.writers {
+		w.Flush()
+		h.files[host].Close()
+	}
+}
+
+func sanitizeHost(host string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-':
+			return r
+		}
+		return '_'
+	}, host)
+	name = strings.Trim(name, ".")
+	if name == "" {
+		return "unknown"
+	}
+	return name
+}
+
+func tagsString(tags []string) string {
+	return "{" + strings.Join(tags, ",") + "}"
+}
+
+var highSeverityTags = map[string]bool{
+	"git-exposed":       true,
+	"env-exposed":       true,
+	"svn-exposed":       true,
+	"webconfig-exposed": true,
+
+	"cors-wildcard-credentials":  true,
+	"cors-reflected-credentials": true,
+}
+
+func hasHighSeverityTag(tags []string) bool {
+	for _, t := range tags {
+		if highSeverityTags[t] {
+			return true
+		}
+	}
+	return false
+}
+
+// softRedirectString renders a soft redirect as "[meta-refresh → <target>]".
+func softRedirectString(r Result) string {
+	return "[" + r.SoftRedirectKind + " → " + r.SoftRedirect + "]"
+}
+
+// chainString renders redirect hops as "302→301→200 <final URL>".
+func chainString(hops []redirectHop) string {
+	codes := make([]string, len(hops))
+	for i, h := range hops {
+		codes[i] = strconv.Itoa(h.Status)
+	}
+	return strings.Join(codes, "→") + " " + hops[len(hops)-1].URL
+}
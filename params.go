@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/valyala/fasthttp"
+)
+
+// With -batch the wordlist holds query parameter names rather than paths.
+// Each request carries -batch of them at once (?a&b&c); a batch whose
+// response differs from the target's baseline holds at least one parameter
+// the application reads, and is split in halves until the parameters
+// themselves are found. A list of n names with one hit costs about
+// n/batch + 2*log2(batch) requests instead of n.
+
+// paramBaseline is a target's response to a query with one parameter no
+// application reads.
+type paramBaseline struct {
+	Status int
+	Hash   uint64
+}
+
+// checkParamBatch disables -batch for multi-keyword scans, where the words
+// of one job are not a single list of names.
+func checkParamBatch() bool {
+	if len(keywords) > 1 {
+		fmt.Println(yellow("Warning: -batch only works with a single wordlist keyword, ignoring it"))
+		return false
+	}
+	return true
+}
+
+// paramURL adds names as bare query parameters to rawURL, after any query
+// it already has.
+func paramURL(rawURL string, names []string) string {
+	base, query, fragment := splitQuery(rawURL)
+	if query != "" {
+		query += "&"
+	}
+	return base + "?" + query + strings.Join(names, "&") + fragment
+}
+
+// scanParams runs the batch-then-narrow search on every target: the feeder
+// hands out one batch per target and -batch words, at most -t at once, and
+// each differing batch is narrowed by the goroutine that sent it.
+func scanParams(ctx context.Context, urls []Target, dirs []job) {
+	client := newClient()
+	names := make([]string, len(dirs))
+	for i, d := range dirs {
+		names[i] = d.Values[0]
+	}
+
+	var sent atomic.Int64
+	p := &paramSearch{client: client, sent: &sent}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, *threads)
+	for _, t := range urls {
+		base, ok := p.baseline(ctx, t)
+		if !ok {
+			continue
+		}
+		for start := 0; start < len(names); start += *paramBatch {
+			end := min(start+*paramBatch, len(names))
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			}
+			wg.Add(1)
+			go func(t Target, batch []string) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				p.narrow(ctx, t, base, batch)
+			}(t, names[start:end])
+		}
+	}
+	wg.Wait()
+	fmt.Println(blue(fmt.Sprintf("[batch] %d requests for %d parameter names on %d targets", sent.Load(), len(names), len(urls))))
+}
+
+type paramSearch struct {
+	client *fasthttp.Client
+	sent   *atomic.Int64
+}
+
+func (p *paramSearch) get(ctx context.Context, t Target, names []string) (int, []byte, bool) {
+	if !takeRequest() || !waitForHost(ctx, hostOf(t.URL)) {
+		return 0, nil, false
+	}
+	p.sent.Add(1)
+	status, body, _, err := getStatusCode(p.client, t, paramURL(t.URL, names))
+	if err != nil {
+		recordError(err)
+		logger.Warn("request failed", "host", hostOf(t.URL), "params", len(names), "error", err)
+		return 0, nil, false
+	}
+	return status, body, true
+}
+
+// baseline fetches the target's response to a random parameter twice and
+// skips targets whose page changes between the two, as every batch would
+// then look like a hit.
+func (p *paramSearch) baseline(ctx context.Context, t Target) (*paramBaseline, bool) {
+	var hashes [2]uint64
+	var status [2]int
+	for i := range hashes {
+		name := randomPath()
+		s, body, ok := p.get(ctx, t, []string{name})
+		if !ok {
+			return nil, false
+		}
+		status[i], hashes[i] = s, simhash(stripReflection(body, name))
+	}
+	if status[0] != status[1] || similarity(hashes[0], hashes[1]) < *similarityThreshold {
+		emitLine(yellow("[batch] response changes between identical requests, skipping") + " " + t.URL)
+		return nil, false
+	}
+	return &paramBaseline{Status: status[0], Hash: hashes[0]}, true
+}
+
+// narrow requests batch and, if the response differs from the baseline,
+// splits it until the single names that make it differ are reported.
+func (p *paramSearch) narrow(ctx context.Context, t Target, base *paramBaseline, batch []string) {
+	if ctx.Err() != nil {
+		return
+	}
+	status, body, ok := p.get(ctx, t, batch)
+	if !ok {
+		return
+	}
+	if status == base.Status && similarity(base.Hash, simhash(stripReflection(body, batch...))) >= *similarityThreshold {
+		return
+	}
+	if len(batch) > 1 {
+		half := len(batch) / 2
+		p.narrow(ctx, t, base, batch[:half])
+		p.narrow(ctx, t, base, batch[half:])
+		return
+	}
+	logger.Info("parameter found", "target", t.URL, "param", batch[0], "status", status)
+	writeResult(Result{
+		URL:    paramURL(t.URL, batch),
+		Host:   hostOf(t.URL),
+		Status: status,
+		Length: len(body),
+		Title:  extractTitle(body),
+		Tags:   []string{"param"},
+	})
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// storedResponse is what the -post-filter store keeps of every response,
+// filtered or not. Bodies are not kept.
+type storedResponse struct {
+	URL         string
+	Host        string
+	Status      int
+	Length      int
+	Title       string
+	ContentType string
+}
+
+type responseStore struct {
+	mu      sync.Mutex
+	max     int
+	rows    []storedResponse
+	dropped int
+}
+
+var responses *responseStore
+
+func (s *responseStore) add(r storedResponse) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.rows) >= s.max {
+		if s.dropped == 0 {
+			fmt.Println(yellow(fmt.Sprintf("Warning: -post-filter store is full (%d responses), later responses are not kept", s.max)))
+		}
+		s.dropped++
+		return
+	}
+	s.rows = append(s.rows, r)
+}
+
+// postCond is one "field op value" clause of a -post-filter expression.
+type postCond struct {
+	field string
+	op    string
+	value string
+	num   int
+	re    *regexp.Regexp
+}
+
+// postFilter is a -post-filter expression: clauses joined by && inside
+// alternatives joined by ||.
+type postFilter [][]postCond
+
+var postOps = []string{"<=", ">=", "!=", "==", "!~", "<", ">", "~"}
+
+// parsePostFilter parses expressions such as
+// `status==200 && length>1000 || title~"(?i)admin"`.
+func parsePostFilter(expr string) (postFilter, error) {
+	var f postFilter
+	for _, alt := range strings.Split(expr, "||") {
+		var conds []postCond
+		for _, clause := range strings.Split(alt, "&&") {
+			c, err := parsePostCond(strings.TrimSpace(clause))
+			if err != nil {
+				return nil, err
+			}
+			conds = append(conds, c)
+		}
+		f = append(f, conds)
+	}
+	return f, nil
+}
+
+func parsePostCond(clause string) (postCond, error) {
+	// The leftmost operator wins so values may contain operator characters.
+	at, op := -1, ""
+	for _, o := range postOps {
+		if i := strings.Index(clause, o); i > 0 && (at < 0 || i < at) {
+			at, op = i, o
+		}
+	}
+	if at < 0 {
+		return postCond{}, fmt.Errorf("%q: expected field, operator and value", clause)
+	}
+
+	c := postCond{
+		field: strings.ToLower(strings.TrimSpace(clause[:at])),
+		op:    op,
+		value: strings.Trim(strings.TrimSpace(clause[at+len(op):]), `"'`),
+	}
+	switch c.field {
+	case "status", "length":
+		if op == "~" || op == "!~" {
+			return c, fmt.Errorf("%q: %s is numeric, use ==, !=, <, <=, > or >=", clause, c.field)
+		}
+		n, err := strconv.Atoi(c.value)
+		if err != nil {
+			return c, fmt.Errorf("%q: %s needs a number", clause, c.field)
+		}
+		c.num = n
+	case "url", "host", "title", "type":
+		switch op {
+		case "~", "!~":
+			re, err := regexp.Compile(c.value)
+			if err != nil {
+				return c, fmt.Errorf("%q: %v", clause, err)
+			}
+			c.re = re
+		case "==", "!=":
+		default:
+			return c, fmt.Errorf("%q: %s is text, use ==, !=, ~ or !~", clause, c.field)
+		}
+	default:
+		return c, fmt.Errorf("%q: unknown field %q (have status, length, url, host, title, type)", clause, c.field)
+	}
+	return c, nil
+}
+
+func (c postCond) match(r storedResponse) bool {
+	switch c.field {
+	case "status", "length":
+		v := r.Status
+		if c.field == "length" {
+			v = r.Length
+		}
+		switch c.op {
+		case "==":
+			return v == c.num
+		case "!=":
+			return v != c.num
+		case "<":
+			return v < c.num
+		case "<=":
+			return v <= c.num
+		case ">":
+			return v > c.num
+		default:
+			return v >= c.num
+		}
+	}
+
+	var s string
+	switch c.field {
+	case "url":
+		s = r.URL
+	case "host":
+		s = r.Host
+	case "title":
+		s = r.Title
+	default:
+		s = contentKind(r.ContentType)
+	}
+	switch c.op {
+	case "==":
+		return s == c.value
+	case "!=":
+		return s != c.value
+	case "~":
+		return c.re.MatchString(s)
+	default:
+		return !c.re.MatchString(s)
+	}
+}
+
+func (f postFilter) match(r storedResponse) bool {
+	for _, conds := range f {
+		ok := true
+		for _, c := range conds {
+			if !c.match(r) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// printPostFiltered prints every stored response matching the -post-filter
+// expression.
+func printPostFiltered(f postFilter) {
+	responses.mu.Lock()
+	defer responses.mu.Unlock()
+
+	var matched []storedResponse
+	for _, r := range responses.rows {
+		if f.match(r) {
+			matched = append(matched, r)
+		}
+	}
+	fmt.Printf("\nPost-filter %q: %d of %d responses\n", *postFilterExpr, len(matched), len(responses.rows))
+	for _, r := range matched {
+		// Not printResult: these rows were counted when the scan reported
+		// them, and the summaries are already printed.
+		printLine(formatResultLine(Result{URL: r.URL, Host: r.Host, Status: r.Status, Length: r.Length, Title: r.Title, ContentType: r.ContentType}))
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// Printing the -post-filter rows after the summaries must not count them a
+// second time.
+func TestPrintPostFilteredKeepsCounts(t *testing.T) {
+	setFlag(t, "report-errors", "true")
+	f, err := parsePostFilter("status >= 200")
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := responses
+	responses = &responseStore{max: 10}
+	t.Cleanup(func() { responses = old })
+	responses.add(storedResponse{URL: "http://post.test/a", Host: "post.test", Status: 200, ContentType: "text/html"})
+	responses.add(storedResponse{URL: "http://post.test/b", Host: "post.test", Status: 500, ContentType: "application/json"})
+
+	statusClassesMu.Lock()
+	classes := statusClasses
+	statusClassesMu.Unlock()
+	serverErrorsMu.Lock()
+	errors500 := serverErrors[500]
+	serverErrorsMu.Unlock()
+	kindCountsMu.Lock()
+	html := kindCounts["html"]
+	kindCountsMu.Unlock()
+
+	out := captureStdout(t, func() { printPostFiltered(f) })
+	if !strings.Contains(out, "http://post.test/a") || !strings.Contains(out, "http://post.test/b") {
+		t.Fatalf("post-filtered rows missing from %q", out)
+	}
+
+	statusClassesMu.Lock()
+	defer statusClassesMu.Unlock()
+	serverErrorsMu.Lock()
+	defer serverErrorsMu.Unlock()
+	kindCountsMu.Lock()
+	defer kindCountsMu.Unlock()
+	if statusClasses != classes || serverErrors[500] != errors500 || kindCounts["html"] != html {
+		t.Error("printing post-filtered rows changed the scan counters")
+	}
+}
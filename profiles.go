@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// profiles are bundles of flag values selected with -profile. Each only
+// fills in flags the user did not set on the command line.
+var profiles = map[string]func(set func(name, value string)){
+	"quick": func(set func(name, value string)) {
+		set("t", "50")
+		set("head-then-get", "true")
+	},
+	"thorough": func(set func(name, value string)) {
+		set("t", "20")
+		set("calibrate", "true")
+		set("recursion", "true")
+		// Following redirects is deliberate: the final page, not the
+		// 301, is what gets reported and calibrated.
+		set("follow", "true")
+		set("sensitive", "true")
+		set("x", "bak,old,orig,swp,zip,tar.gz")
+		set("methods-probe", "true")
+	},
+	"stealth": func(set func(name, value string)) {
+		set("t", "2")
+		set("adaptive-delay", "true")
+		set("min-delay", "500ms")
+		set("max-delay", "10s")
+		set("random-agent", "true")
+		set("waf-evasion", "true")
+	},
+}
+
+// applyProfile applies the -profile preset on top of the parsed flags,
+// leaving any flag given explicitly alone.
+func applyProfile(name string) error {
+	apply, ok := profiles[name]
+	if !ok {
+		names := make([]string, 0, len(profiles))
+		for n := range profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("unknown profile %q (have %s)", name, strings.Join(names, ", "))
+	}
+
+	explicit := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	var err error
+	apply(func(flagName, value string) {
+		if err == nil && !explicit[flagName] {
+			err = flag.Set(flagName, value)
+		}
+	})
+	return err
+}
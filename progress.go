@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	progressTotal atomic.Int64
+	progressDone  atomic.Int64
+	matchCount    atomic.Int64
+)
+
+// progressMu serializes the progress line with result lines so a result
+// never lands in the middle of it.
+var (
+	progressMu    sync.Mutex
+	progressShown bool
+)
+
+// stderrIsTerminal reports whether the progress line has somewhere to go;
+// redirected stderr would fill up with carriage returns.
+func stderrIsTerminal() bool {
+	fi, err := os.Stderr.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// startProgress redraws the progress line every second until the returned
+// function is called, which also erases it.
+func startProgress() (stop func()) {
+	progressDone.Store(0)
+	progressTotal.Store(0)
+	matchCount.Store(0)
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				drawProgress()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+		clearProgress()
+	}
+}
+
+func drawProgress() {
+	done, total := progressDone.Load(), progressTotal.Load()
+	pct := 0.0
+	if total > 0 {
+		pct = float64(done) * 100 / float64(total)
+	}
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	fmt.Fprintf(os.Stderr, "\r\033[K%d/%d requests (%.0f%%), %s", done, total, pct, green(fmt.Sprintf("%d matches", matchCount.Load())))
+	progressShown = true
+}
+
+// printLine prints a result line, erasing the progress line first; it is
+// redrawn on the next tick.
+func printLine(line string) {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	eraseProgress()
+	fmt.Println(line)
+}
+
+func clearProgress() {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	eraseProgress()
+}
+
+func eraseProgress() {
+	if progressShown {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+		progressShown = false
+	}
+}
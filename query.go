@@ -0,0 +1,64 @@
+package main
+
+import (
+	neturl "net/url"
+	"strings"
+)
+
+// parseQueryParams validates -query and splits it into raw key=value pairs.
+// The pairs are kept as written so wordlist keywords inside them survive.
+func parseQueryParams(s string) ([]string, error) {
+	if _, err := neturl.ParseQuery(s); err != nil {
+		return nil, err
+	}
+	var pairs []string
+	for _, p := range strings.Split(s, "&") {
+		if p != "" {
+			pairs = append(pairs, p)
+		}
+	}
+	return pairs, nil
+}
+
+var queryParams []string
+
+// addQuery merges the -query parameters into every target's query string.
+// A parameter the target already has is replaced by the -query value.
+func addQuery(urls []Target) []Target {
+	if len(queryParams) == 0 {
+		return urls
+	}
+	override := make(map[string]bool, len(queryParams))
+	for _, p := range queryParams {
+		override[queryKey(p)] = true
+	}
+	for i := range urls {
+		base, raw, fragment := splitQuery(urls[i].URL)
+		var kept []string
+		for _, p := range strings.Split(raw, "&") {
+			if p != "" && !override[queryKey(p)] {
+				kept = append(kept, p)
+			}
+		}
+		urls[i].URL = base + "?" + strings.Join(append(kept, queryParams...), "&") + fragment
+	}
+	return urls
+}
+
+func queryKey(pair string) string {
+	k, _, _ := strings.Cut(pair, "=")
+	if unescaped, err := neturl.QueryUnescape(k); err == nil {
+		return unescaped
+	}
+	return k
+}
+
+// splitQuery splits a URL into the part before the query, the raw query and
+// the fragment including its '#'.
+func splitQuery(u string) (base, query, fragment string) {
+	if i := strings.IndexByte(u, '#'); i >= 0 {
+		u, fragment = u[:i], u[i:]
+	}
+	base, query, _ = strings.Cut(u, "?")
+	return base, query, fragment
+}
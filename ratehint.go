@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// rateHintThreshold is the share of requests answered 429/503 or timed
+	// out above which the end of the scan suggests slower settings.
+	rateHintThreshold = 0.1
+	// rateHintMinRequests keeps a handful of failures on a tiny scan from
+	// triggering the hint.
+	rateHintMinRequests = 50
+)
+
+var (
+	requestsSent atomic.Int64
+	rateLimited  atomic.Int64
+)
+
+// noteResponse counts a sent request for the rate-limit hint.
+func noteResponse(status int) {
+	requestsSent.Add(1)
+	if status == 429 || status == 503 {
+		rateLimited.Add(1)
+	}
+}
+
+// printRateHint suggests fewer threads and a delay when many requests were
+// rate limited or timed out. The suggested thread count shrinks with the
+// failure rate, and the delay spreads those threads over the request rate
+// the server did accept.
+func printRateHint(elapsed time.Duration) {
+	sent := requestsSent.Load()
+	errorCountsMu.Lock()
+	timeouts := int64(errorCounts[errTimeout])
+	errorCountsMu.Unlock()
+	total := sent + timeouts
+	failed := rateLimited.Load() + timeouts
+	if total < rateHintMinRequests || elapsed <= 0 {
+		return
+	}
+	rate := float64(failed) / float64(total)
+	if rate < rateHintThreshold {
+		return
+	}
+
+	threads := int(float64(*threads) * (1 - rate) / 2)
+	if threads < 1 {
+		threads = 1
+	}
+	suggestion := fmt.Sprintf("-t %d", threads)
+	if accepted := float64(total-failed) / elapsed.Seconds(); accepted > 0 {
+		delay := time.Duration(float64(threads) / accepted * float64(time.Second)).Round(10 * time.Millisecond)
+		if delay > 0 {
+			suggestion += fmt.Sprintf(" -adaptive-delay -min-delay %s", delay)
+		}
+	}
+	fmt.Println(yellow(fmt.Sprintf("[hint] %.0f%% of requests were rate limited (429/503) or timed out; try %s", rate*100, suggestion)))
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// recursionStatus holds the -recursion-status codes; nil means the default
+// of descending into directory-like responses only.
+var recursionStatus map[int]bool
+
+// recurser collects the directories found during a round, to be scanned
+// with the same wordlist in the next one.
+type recurser struct {
+	mu      sync.Mutex
+	visited map[string]bool
+	next    []Target
+}
+
+var recursion *recurser
+
+func newRecurser(urls []Target) *recurser {
+	r := &recurser{visited: make(map[string]bool)}
+	for _, t := range urls {
+		r.visited[dirKey(t.URL)] = true
+	}
+	return r
+}
+
+// consider queues res as a directory to descend into when it qualifies.
+// location is the response's Location header.
+func (r *recurser) consider(t Target, res Result, location string) {
+	if !shouldRecurse(res, location) {
+		return
+	}
+	key := dirKey(res.URL)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.visited[key] {
+		return
+	}
+	r.visited[key] = true
+	sub := t
+	sub.URL = res.URL
+	sub.schemePair = pairNone
+	r.next = append(r.next, sub)
+}
+
+// nextRound returns the directories queued since the last call.
+func (r *recurser) nextRound() []Target {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	next := r.next
+	r.next = nil
+	return next
+}
+
+// shouldRecurse applies -recursion-status, or by default treats as a
+// directory a redirect to the same path with a trailing slash, and a 2xx,
+// 401 or 403 for a path ending in one (also after -follow took that
+// redirect).
+func shouldRecurse(res Result, location string) bool {
+	if recursionStatus != nil {
+		return recursionStatus[res.Status]
+	}
+	base, _, _ := splitQuery(res.URL)
+	final := base
+	if len(res.Redirects) > 0 {
+		final, _, _ = splitQuery(res.Redirects[len(res.Redirects)-1].URL)
+	}
+	switch {
+	case res.Status >= 300 && res.Status < 400:
+		loc, _, _ := splitQuery(location)
+		return strings.HasSuffix(loc, "/") && strings.HasSuffix(base+"/", loc)
+	case strings.HasSuffix(final, "/") && (final == base || final == base+"/"):
+		return res.Status >= 200 && res.Status < 300 || res.Status == 401 || res.Status == 403
+	}
+	return false
+}
+
+func dirKey(rawURL string) string {
+	base, _, _ := splitQuery(rawURL)
+	return strings.TrimRight(base, "/")
+}
+
+func printRecursionRound(depth, n int) {
+	fmt.Println(blue(fmt.Sprintf("[recursion] depth %d: %d new directories", depth, n)))
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// remoteWordlistTimeout bounds a whole wordlist download.
+const remoteWordlistTimeout = 5 * time.Minute
+
+func isRemoteWordlist(spec string) bool {
+	return strings.HasPrefix(spec, "http://") || strings.HasPrefix(spec, "https://")
+}
+
+// fetchWordlist downloads a -w URL to a local file and returns its path and
+// whether it is a temporary file the caller should remove. With
+// -wordlist-cache the file is kept there and reused on later runs.
+func fetchWordlist(rawURL string) (string, bool, error) {
+	var dest string
+	if *wordlistCache != "" {
+		sum := sha256.Sum256([]byte(rawURL))
+		dest = filepath.Join(*wordlistCache, hex.EncodeToString(sum[:8])+"-"+path.Base(rawURL))
+		if fi, err := os.Stat(dest); err == nil && fi.Size() > 0 {
+			return dest, false, nil
+		}
+		if err := os.MkdirAll(*wordlistCache, 0755); err != nil {
+			return "", false, err
+		}
+	}
+
+	client := &http.Client{Timeout: remoteWordlistTimeout}
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("%s: %s", rawURL, resp.Status)
+	}
+
+	// Download next to the destination and rename once complete, so an
+	// interrupted download never ends up in the cache.
+	dir := os.TempDir()
+	if dest != "" {
+		dir = *wordlistCache
+	}
+	f, err := os.CreateTemp(dir, "dirscan-wordlist-*")
+	if err != nil {
+		return "", false, err
+	}
+	n, err := io.Copy(f, resp.Body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n == 0 {
+		err = fmt.Errorf("%s: empty response", rawURL)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", false, err
+	}
+	if dest == "" {
+		return f.Name(), true, nil
+	}
+	if err := os.Rename(f.Name(), dest); err != nil {
+		os.Remove(f.Name())
+		return "", false, err
+	}
+	return dest, false, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	requestIDMu   sync.Mutex
+	requestIDFile *os.File
+)
+
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// setRequestID gives req a fresh X-Request-ID and appends it with the
+// request to -request-id-log, if set.
+func setRequestID(req *fasthttp.Request) {
+	id := newUUID()
+	req.Header.Set("X-Request-ID", id)
+	if requestIDFile == nil {
+		return
+	}
+	requestIDMu.Lock()
+	fmt.Fprintf(requestIDFile, "%s\t%s\t%s\t%s\n", time.Now().Format(time.RFC3339), id, req.Header.Method(), req.URI().FullURI())
+	requestIDMu.Unlock()
+}
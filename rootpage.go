@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	neturl "net/url"
+	"sync"
+)
+
+// rootPage is a host's homepage, which single-page apps serve in place of
+// every route they do not know.
+type rootPage struct {
+	Status int
+	Body   []byte
+	Hash   uint64
+}
+
+var rootPages sync.Map // root URL -> *rootPage
+
+// rootURL returns scheme://host/ for rawURL, so every base path and
+// recursed directory on a host shares one homepage.
+func rootURL(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Scheme + "://" + u.Host + "/"
+}
+
+// captureRootPages fetches the homepage of every target host for
+// -filter-root.
+func captureRootPages(urls []Target) {
+	client := newClient()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, *threads)
+	seen := make(map[string]bool)
+	for _, t := range urls {
+		root := rootURL(t.URL)
+		if seen[root] {
+			continue
+		}
+		seen[root] = true
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(t Target, root string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if !takeRequest() {
+				return
+			}
+			statusCode, body, _, err := getStatusCode(client, t, root)
+			if err != nil {
+				emitLine(fmt.Sprint(yellow("[filter-root] could not fetch "), root, " ", err))
+				logger.Warn("homepage fetch failed", "root", root, "error", err)
+				return
+			}
+			logger.Info("homepage captured", "root", root, "status", statusCode, "length", len(body))
+			rootPages.Store(root, &rootPage{Status: statusCode, Body: body, Hash: simhash(body)})
+		}(t, root)
+	}
+	wg.Wait()
+}
+
+// isRootPage reports whether a response is the host's homepage again: the
+// same status and a byte-identical body, or with -root-similarity below 1 a
+// body at least that similar once the requested words are stripped from it.
+// A wordlist entry such as index.html is hidden too, being the same page.
+func isRootPage(baseURL string, dir job, status int, body []byte) bool {
+	v, ok := rootPages.Load(rootURL(baseURL))
+	if !ok {
+		return false
+	}
+	root := v.(*rootPage)
+	if root.Status != status {
+		return false
+	}
+	if bytes.Equal(root.Body, body) {
+		return true
+	}
+	if *rootSimilarity >= 1 {
+		return false
+	}
+	return similarity(root.Hash, simhash(stripReflection(body, dir.Values...))) >= *rootSimilarity
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net"
+	"strings"
+)
+
+// Positions of a target within an http/https pair made by -both-schemes.
+const (
+	pairNone = iota
+	pairFirst
+	pairSecond
+)
+
+type heldResult struct {
+	res  Result
+	emit func(Result)
+}
+
+// portSchemes are the schemes inferred from the port of a target given
+// without one.
+var portSchemes = map[string]string{
+	"80":   "http",
+	"8080": "http",
+	"443":  "https",
+	"8443": "https",
+}
+
+// schemeFor picks the scheme for a target without one: by port when it is a
+// well-known one, otherwise -default-scheme.
+func schemeFor(host string) string {
+	hostport := host
+	if i := strings.IndexAny(hostport, "/?#"); i >= 0 {
+		hostport = hostport[:i]
+	}
+	if _, port, err := net.SplitHostPort(hostport); err == nil {
+		if scheme, ok := portSchemes[port]; ok {
+			return scheme
+		}
+	}
+	return *defaultScheme
+}
+
+// addSchemes gives targets without a scheme the one schemeFor picks, or with
+// -both-schemes an http:// and an https:// twin placed next to each other.
+func addSchemes(urls []Target) []Target {
+	out := make([]Target, 0, len(urls))
+	for _, t := range urls {
+		if strings.Contains(t.URL, "://") {
+			out = append(out, t)
+			continue
+		}
+		host := strings.TrimLeft(t.URL, "/")
+		if !*bothSchemes {
+			t.URL = schemeFor(host) + "://" + host
+			out = append(out, t)
+			continue
+		}
+		plain, secure := t, t
+		plain.URL, plain.schemePair = "http://"+host, pairFirst
+		secure.URL, secure.schemePair = "https://"+host, pairSecond
+		out = append(out, plain, secure)
+	}
+	return out
+}
+
+// sameAcrossSchemes reports whether the http and https responses for a path
+// are the same page.
+func sameAcrossSchemes(a, b Result) bool {
+	return a.Status == b.Status && a.Length == b.Length && a.Title == b.Title &&
+		strings.TrimPrefix(a.URL, "http://") == strings.TrimPrefix(b.URL, "https://")
+}
@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+func TestAddSchemesByPort(t *testing.T) {
+	tests := []struct {
+		defaultScheme, target, want string
+	}{
+		{"http", "example.com", "http://example.com"},
+		{"http", "example.com:80", "http://example.com:80"},
+		{"http", "example.com:8080/app", "http://example.com:8080/app"},
+		{"http", "example.com:443", "https://example.com:443"},
+		{"http", "example.com:8443/app?x=1", "https://example.com:8443/app?x=1"},
+		{"http", "10.0.0.1:9000", "http://10.0.0.1:9000"},
+		{"http", "[::1]:443", "https://[::1]:443"},
+		{"http", "//example.com:443", "https://example.com:443"},
+		{"http", "https://example.com:80", "https://example.com:80"},
+
+		// -default-scheme only covers ports the table does not know.
+		{"https", "example.com", "https://example.com"},
+		{"https", "10.0.0.1:9000", "https://10.0.0.1:9000"},
+		{"https", "example.com:80", "http://example.com:80"},
+		{"https", "example.com:8080", "http://example.com:8080"},
+		{"https", "example.com:8443", "https://example.com:8443"},
+		{"https", "http://example.com:9000", "http://example.com:9000"},
+	}
+	for _, tt := range tests {
+		setFlag(t, "default-scheme", tt.defaultScheme)
+		got := addSchemes([]Target{{URL: tt.target}})
+		if len(got) != 1 || got[0].URL != tt.want {
+			t.Errorf("-default-scheme %s: %q became %v, want %q", tt.defaultScheme, tt.target, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// With -stop-on-first a wordlist line starting with '#' begins a new
+// section, the rest of the line naming it:
+//
+//	# wordpress
+//	wp-login.php
+//	wp-content/
+//	# drupal
+//	core/CHANGELOG.txt
+//
+// Words before the first marker form a section of their own. Once a section
+// has a match on a target, its remaining words are skipped there.
+
+// sectionJobs splits words at the section markers and returns one job per
+// word, with -x, -prefix and -suffix applied within each section. Sections
+// are numbered from 1; 0 means the job belongs to none.
+func sectionJobs(words []string) []job {
+	var jobs []job
+	section := 1
+	start := 0
+	flush := func(end int) {
+		for _, w := range expandWords(addExtensions(words[start:end])) {
+			jobs = append(jobs, job{Values: []string{w}, section: section})
+		}
+	}
+	for i, w := range words {
+		if strings.HasPrefix(w, "#") {
+			flush(i)
+			section++
+			start = i + 1
+		}
+	}
+	flush(len(words))
+	return jobs
+}
+
+// checkStopOnFirst disables -stop-on-first for multi-keyword scans, where a
+// section marker has no single list to split.
+func checkStopOnFirst() bool {
+	if len(keywords) > 1 {
+		fmt.Println(yellow("Warning: -stop-on-first only works with a single wordlist keyword, ignoring it"))
+		return false
+	}
+	return true
+}
+
+var sectionsDone sync.Map // target URL + section -> struct{}
+
+func sectionKey(targetURL string, section int) string {
+	return fmt.Sprintf("%s\x00%d", targetURL, section)
+}
+
+// sectionDone reports whether the job's section already has a match on the
+// target.
+func sectionDone(targetURL string, j job) bool {
+	if j.section == 0 {
+		return false
+	}
+	_, done := sectionsDone.Load(sectionKey(targetURL, j.section))
+	return done
+}
+
+// claimSection records a match for the job's section on the target and
+// reports whether it was the first, so matches from requests already in
+// flight do not add a second one.
+func claimSection(targetURL string, j job) bool {
+	if j.section == 0 {
+		return true
+	}
+	_, done := sectionsDone.LoadOrStore(sectionKey(targetURL, j.section), struct{}{})
+	return !done
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSectionJobs(t *testing.T) {
+	jobs := sectionJobs([]string{"a", "#cms", "b", "c", "# other", "d"})
+	want := []struct {
+		word    string
+		section int
+	}{{"a", 1}, {"b", 2}, {"c", 2}, {"d", 3}}
+	if len(jobs) != len(want) {
+		t.Fatalf("got %d jobs, want %d", len(jobs), len(want))
+	}
+	for i, w := range want {
+		if jobs[i].Values[0] != w.word || jobs[i].section != w.section {
+			t.Errorf("job %d = %q in section %d, want %q in %d", i, jobs[i].Values[0], jobs[i].section, w.word, w.section)
+		}
+	}
+}
+
+// With -interval every pass must scan the sections again, not only the
+// first.
+func TestStopOnFirstEveryPass(t *testing.T) {
+	srv := newCountingServer(t, "/x")
+	setFlag(t, "stop-on-first", "true")
+	setFlag(t, "interval", "1h")
+	startOutputs(t)
+
+	dirs := sectionJobs([]string{"x", "y", "# second", "z"})
+	urls := []Target{{URL: srv.URL + "/"}}
+	groups := []scanGroup{{dirs: dirs, targets: urls}}
+	for pass := 1; pass <= 2; pass++ {
+		startPass(pass)
+		runPass(context.Background(), dirs, urls, groups, false, nil)
+	}
+	t.Cleanup(func() { baseline = nil })
+
+	for _, path := range []string{"/x", "/z"} {
+		if n := srv.count(path); n != 2 {
+			t.Errorf("%s requested %d times over two passes, want 2", path, n)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// seenSet remembers keys; Add reports whether key had not been seen before.
+type seenSet interface {
+	Add(key string) bool
+}
+
+type mapSeenSet struct {
+	mu   sync.Mutex
+	keys map[string]struct{}
+}
+
+func newMapSeenSet() *mapSeenSet {
+	return &mapSeenSet{keys: make(map[string]struct{})}
+}
+
+func (s *mapSeenSet) Add(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.keys[key]; ok {
+		return false
+	}
+	s.keys[key] = struct{}{}
+	return true
+}
+
+// seenStore is the -seen-file: URLs reported by earlier runs, loaded at
+// startup, with new discoveries appended as they are found.
+type seenStore struct {
+	set  seenSet
+	mu   sync.Mutex
+	file *os.File
+	w    *bufio.Writer
+}
+
+var seenURLs *seenStore
+
+func openSeenStore(path string) (*seenStore, error) {
+	s := &seenStore{set: newMapSeenSet()}
+	if *bloom {
+		s.set = newBloomSeenSet(*bloomCapacity, *bloomFP)
+	}
+
+	if file, err := openInput(path); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				s.set.Add(line)
+			}
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	s.file = file
+	s.w = bufio.NewWriter(file)
+	return s, nil
+}
+
+// MarkNew records url and reports whether it is a new discovery.
+func (s *seenStore) MarkNew(url string) bool {
+	if !s.set.Add(url) {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintln(s.w, url)
+	return true
+}
+
+func (s *seenStore) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.w.Flush()
+	s.file.Close()
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"sync"
+)
+
+type sensitiveFile struct {
+	Path  string
+	Tag   string
+	Match func(body []byte) bool
+}
+
+var (
+	gitHeadRe = regexp.MustCompile(`^(ref: refs/|[0-9a-f]{40}\s*$)`)
+	envLineRe = regexp.MustCompile(`(?m)^[A-Za-z_][A-Za-z0-9_]*=`)
+	svnRe     = regexp.MustCompile(`^(\d+\s*\n|SQLite format 3)`)
+)
+
+// Each file must match its signature, not just return 200, so catch-all
+// pages that answer every path are not reported as exposures.
+var sensitiveFiles = []sensitiveFile{
+	{"/.git/HEAD", "git-exposed", func(b []byte) bool { return gitHeadRe.Match(b) }},
+	{"/.git/config", "git-exposed", func(b []byte) bool { return bytes.Contains(b, []byte("[core]")) }},
+	{"/.env", "env-exposed", func(b []byte) bool {
+		return !bytes.Contains(bytes.ToLower(b), []byte("<html")) && envLineRe.Match(b)
+	}},
+	{"/.svn/entries", "svn-exposed", func(b []byte) bool { return svnRe.Match(b) }},
+	{"/.svn/wc.db", "svn-exposed", func(b []byte) bool { return bytes.HasPrefix(b, []byte("SQLite format 3")) }},
+	{"/.DS_Store", "ds-store-exposed", func(b []byte) bool { return bytes.HasPrefix(b, []byte("\x00\x00\x00\x01Bud1")) }},
+	{"/web.config", "webconfig-exposed", func(b []byte) bool { return bytes.Contains(b, []byte("<configuration")) }},
+}
+
+func scanSensitive(urls []Target) {
+	client := newClient()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, *threads)
+	for _, t := range urls {
+		for _, f := range sensitiveFiles {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(t Target, f sensitiveFile) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				if !takeRequest() {
+					return
+				}
+				target := probeURL(t.URL, f.Path)
+				statusCode, body, meta, err := getStatusCode(client, t, target)
+				if err != nil || statusCode != 200 || !f.Match(body) {
+					return
+				}
+				writeResult(Result{
+					URL:    target,
+					Host:   hostOf(t.URL),
+					Status: statusCode,
+					Length: len(body),
+					Title:  extractTitle(body),
+					Tags:   []string{f.Tag},
+
+					EffectiveURL: meta.EffectiveURL,
+				})
+			}(t, f)
+		}
+	}
+	wg.Wait()
+}
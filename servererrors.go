@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// serverErrorColor marks 5xx statuses under -report-errors, apart from the
+// plain red of other unusual codes.
+var serverErrorColor = color.New(color.FgWhite, color.BgRed, color.Bold).SprintFunc()
+
+var (
+	serverErrorsMu sync.Mutex
+	serverErrors   = make(map[int]int) // 5xx status -> printed results
+)
+
+func isServerError(status int) bool {
+	return status >= 500 && status < 600
+}
+
+// surfaceServerError reports whether -report-errors keeps a response that
+// the status, soft-404, calibration and title filters would hide.
+func surfaceServerError(status int) bool {
+	return *reportErrors && isServerError(status)
+}
+
+func countServerError(status int) {
+	if !*reportErrors || !isServerError(status) {
+		return
+	}
+	serverErrorsMu.Lock()
+	serverErrors[status]++
+	serverErrorsMu.Unlock()
+}
+
+// printServerErrorSummary prints the -report-errors count per 5xx status.
+func printServerErrorSummary() {
+	serverErrorsMu.Lock()
+	defer serverErrorsMu.Unlock()
+
+	codes := make([]int, 0, len(serverErrors))
+	total := 0
+	for code, n := range serverErrors {
+		codes = append(codes, code)
+		total += n
+	}
+	if total == 0 {
+		return
+	}
+	sort.Ints(codes)
+	parts := make([]string, len(codes))
+	for i, code := range codes {
+		parts[i] = fmt.Sprintf("%d %d", code, serverErrors[code])
+	}
+	fmt.Printf("%s %d (%s)\n", serverErrorColor("Server errors:"), total, strings.Join(parts, ", "))
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// parseShard parses "N/M" with 1 <= N <= M.
+func parseShard(s string) (int, int, error) {
+	a, b, ok := strings.Cut(s, "/")
+	if !ok {
+		return 0, 0, fmt.Errorf("expected N/M, got %q", s)
+	}
+	n, err := strconv.Atoi(a)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid shard index %q", a)
+	}
+	m, err := strconv.Atoi(b)
+	if err != nil || m < 1 {
+		return 0, 0, fmt.Errorf("invalid shard count %q", b)
+	}
+	if n < 1 || n > m {
+		return 0, 0, fmt.Errorf("shard index must be between 1 and %d", m)
+	}
+	return n, m, nil
+}
+
+// shardJobs keeps the jobs whose words hash into shard n of m. Hashing each
+// job makes the split independent of wordlist order, so every machine agrees
+// on which words it owns.
+func shardJobs(jobs []job, n, m int) []job {
+	var kept []job
+	for _, j := range jobs {
+		h := fnv.New32a()
+		h.Write([]byte(j.key()))
+		if int(h.Sum32()%uint32(m)) == n-1 {
+			kept = append(kept, j)
+		}
+	}
+	return kept
+}
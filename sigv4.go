@@ -0,0 +1,167 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// awsCredentials signs requests with AWS Signature Version 4. Keys come from
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and the optional
+// AWS_SESSION_TOKEN; the region from -aws-region, AWS_REGION or
+// AWS_DEFAULT_REGION.
+type awsCredentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Region       string
+	Service      string
+}
+
+var awsCreds *awsCredentials
+
+func loadAWSCredentials(region, service string) (*awsCredentials, error) {
+	c := &awsCredentials{
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		Region:       region,
+		Service:      service,
+	}
+	if c.Region == "" {
+		c.Region = os.Getenv("AWS_REGION")
+	}
+	if c.Region == "" {
+		c.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	switch {
+	case c.AccessKey == "" || c.SecretKey == "":
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY must be set")
+	case c.Region == "":
+		return nil, fmt.Errorf("no region: set -aws-region or AWS_REGION")
+	case c.Service == "":
+		return nil, fmt.Errorf("-aws-service must not be empty")
+	}
+	return c, nil
+}
+
+// sign adds the X-Amz-Date and Authorization headers for req as it is about
+// to be sent. It must run after every other header has been set.
+func (c *awsCredentials) sign(req *fasthttp.Request, now time.Time) {
+	amzDate := now.UTC().Format("20060102T150405Z")
+	day := amzDate[:8]
+	payload := sha256Hex(req.Body())
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	headers := map[string]string{
+		"host":       string(req.URI().Host()),
+		"x-amz-date": amzDate,
+	}
+	if c.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payload)
+		headers["x-amz-content-sha256"] = payload
+	}
+	if c.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", c.SessionToken)
+		headers["x-amz-security-token"] = c.SessionToken
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		string(req.Header.Method()),
+		c.canonicalPath(string(req.URI().Path())),
+		canonicalQuery(req.URI().QueryArgs()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payload,
+	}, "\n")
+
+	scope := day + "/" + c.Region + "/" + c.Service + "/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+c.SecretKey), day)
+	key = hmacSHA256(key, c.Region)
+	key = hmacSHA256(key, c.Service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		c.AccessKey, scope, signedHeaders, signature))
+}
+
+// canonicalPath encodes every path segment; services other than S3 expect
+// the already encoded path to be encoded a second time.
+func (c *awsCredentials) canonicalPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		s = awsEscape(s)
+		if c.Service != "s3" {
+			s = awsEscape(s)
+		}
+		segments[i] = s
+	}
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(args *fasthttp.Args) string {
+	var pairs [][2]string
+	args.VisitAll(func(k, v []byte) {
+		pairs = append(pairs, [2]string{awsEscape(string(k)), awsEscape(string(v))})
+	})
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	parts := make([]string, len(pairs))
+	for i, p := range pairs {
+		parts[i] = p[0] + "=" + p[1]
+	}
+	return strings.Join(parts, "&")
+}
+
+// awsEscape percent-encodes everything but the RFC 3986 unreserved characters.
+func awsEscape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
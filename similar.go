@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"hash/fnv"
+	"math/bits"
+	neturl "net/url"
+)
+
+const shingleSize = 4
+
+// simhash computes a 64-bit locality-sensitive hash over overlapping byte
+// shingles of body, so pages that differ only by a few bytes (an echoed
+// path, a timestamp) end up a small Hamming distance apart.
+func simhash(body []byte) uint64 {
+	if len(body) < shingleSize {
+		h := fnv.New64a()
+		h.Write(body)
+		return h.Sum64()
+	}
+
+	var weights [64]int
+	for i := 0; i+shingleSize <= len(body); i++ {
+		h := fnv.New64a()
+		h.Write(body[i : i+shingleSize])
+		sum := mix64(h.Sum64())
+		for b := 0; b < 64; b++ {
+			if sum&(1<<uint(b)) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+
+	var hash uint64
+	for b := 0; b < 64; b++ {
+		if weights[b] > 0 {
+			hash |= 1 << uint(b)
+		}
+	}
+	return hash
+}
+
+// stripReflection removes echoes of the requested words from body so soft-404
+// pages that print the missing path hash the same for every word.
+func stripReflection(body []byte, words ...string) []byte {
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		body = bytes.ReplaceAll(body, []byte(word), nil)
+		if escaped := neturl.PathEscape(word); escaped != word {
+			body = bytes.ReplaceAll(body, []byte(escaped), nil)
+		}
+	}
+	return body
+}
+
+// similarity returns a score in [0, 1], where 1 means identical hashes.
+func similarity(a, b uint64) float64 {
+	return 1 - float64(bits.OnesCount64(a^b))/64
+}
+
+// mix64 is the splitmix64 finalizer; FNV alone leaves the bits of short
+// shingles poorly distributed.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,81 @@
+package main
+
+// Sink is a destination for reported results: the terminal table and every
+// output file. All sinks are fed from one goroutine in the order results
+// were reported, so a Sink needs no locking of its own and every sink sees
+// results in the same order.
+type Sink interface {
+	Write(r Result)
+	Close()
+}
+
+// sinkQueueSize is how many results can wait for the output goroutine
+// before writeResult blocks.
+const sinkQueueSize = 256
+
+// sinkItem is a result to report, or with terminalOnly a line for the
+// table alone (a -baseline result that is gone), or a status line such as
+// a -max-hits notice. A non-nil flushed is a marker closed once everything
+// queued before it is written.
+type sinkItem struct {
+	r            Result
+	terminalOnly bool
+	line         string
+	flushed      chan struct{}
+}
+
+// sinkQueue is the output goroutine and the queue feeding it.
+type sinkQueue struct {
+	items chan sinkItem
+	done  chan struct{}
+}
+
+var resultQueue *sinkQueue
+
+func startSinkQueue() *sinkQueue {
+	q := &sinkQueue{items: make(chan sinkItem, sinkQueueSize), done: make(chan struct{})}
+	go q.run()
+	return q
+}
+
+func (q *sinkQueue) run() {
+	defer close(q.done)
+	for it := range q.items {
+		switch {
+		case it.flushed != nil:
+			close(it.flushed)
+		case it.line != "":
+			printLine(it.line)
+		case it.terminalOnly:
+			terminal.Write(it.r)
+		default:
+			deliver(it.r)
+		}
+	}
+}
+
+// stop writes out what is queued and ends the goroutine. The caller holds
+// outputsMu for writing, so nothing is queued after it.
+func (q *sinkQueue) stop() {
+	close(q.items)
+	<-q.done
+}
+
+// deliver prints r, unless -baseline finds it unchanged, and hands it to
+// the outputs.
+func deliver(r Result) {
+	if baseline == nil || diffAgainstBaseline(&r) {
+		terminal.Write(r)
+	}
+	for _, w := range outputs {
+		w.Write(r)
+	}
+}
+
+// tableSink is the terminal table.
+type tableSink struct{}
+
+func (tableSink) Write(r Result) { printResult(r) }
+func (tableSink) Close()         {}
+
+var terminal Sink = tableSink{}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// captureStdout redirects os.Stdout while fn runs and returns what it
+// printed.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	done := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- string(b)
+	}()
+	defer func() {
+		os.Stdout = stdout
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+// Results and status lines from many workers at once must come out as
+// whole lines, on the terminal and in the -o file alike.
+func TestSinkNoInterleaving(t *testing.T) {
+	const workers, perWorker = 64, 200
+	textPath := filepath.Join(t.TempDir(), "out.txt")
+	setFlag(t, "o", textPath)
+
+	resultLine := regexp.MustCompile(`^http://stress\.test/w(\d+)/r(\d+) +200 +title-w\d+-r\d+$`)
+	noticeLine := regexp.MustCompile(`^\[notice\] w(\d+) n(\d+)$`)
+
+	out := captureStdout(t, func() {
+		outputs, outputsClosed = nil, false
+		if err := openOutputs(); err != nil {
+			t.Error(err)
+		}
+		var wg sync.WaitGroup
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < perWorker; i++ {
+					writeResult(Result{
+						URL:    fmt.Sprintf("http://stress.test/w%d/r%d", w, i),
+						Host:   "stress.test",
+						Status: 200,
+						Title:  fmt.Sprintf("title-w%d-r%d", w, i),
+					})
+					if i%10 == 0 {
+						emitLine(fmt.Sprintf("[notice] w%d n%d", w, i))
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+		flushOutputs()
+		closeOutputs()
+	})
+
+	results, notices := 0, 0
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		switch {
+		case resultLine.MatchString(strings.TrimSpace(line)):
+			results++
+		case noticeLine.MatchString(line):
+			notices++
+		default:
+			t.Fatalf("mangled terminal line %q", line)
+		}
+	}
+	if results != workers*perWorker || notices != workers*perWorker/10 {
+		t.Errorf("got %d results and %d notices, want %d and %d", results, notices, workers*perWorker, workers*perWorker/10)
+	}
+
+	f, err := os.Open(textPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	lines := 0
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		if !resultLine.MatchString(strings.TrimSpace(sc.Text())) {
+			t.Fatalf("mangled -o line %q", sc.Text())
+		}
+		seen[strings.Fields(sc.Text())[0]] = true
+		lines++
+	}
+	if lines != workers*perWorker || len(seen) != lines {
+		t.Errorf("-o has %d lines, %d distinct, want %d", lines, len(seen), workers*perWorker)
+	}
+}
+
+// A failing -exec command reports from its own goroutine while the output
+// goroutine waits for a free -exec slot; neither may block the other.
+func TestExecFailuresDoNotBlockOutput(t *testing.T) {
+	setFlag(t, "exec", "false {url}")
+	setFlag(t, "exec-workers", "1")
+
+	done := make(chan string)
+	go func() {
+		done <- captureStdout(t, func() {
+			outputs, outputsClosed = nil, false
+			if err := openOutputs(); err != nil {
+				t.Error(err)
+			}
+			for i := 0; i < 2000; i++ {
+				writeResult(Result{URL: fmt.Sprintf("http://exec.test/r%d", i), Host: "exec.test", Status: 200})
+			}
+			closeOutputs()
+		})
+	}()
+	select {
+	case out := <-done:
+		if n := strings.Count(out, "Exec hook failed"); n != 2000 {
+			t.Errorf("got %d exec failure notices, want 2000", n)
+		}
+	case <-time.After(20 * time.Second):
+		t.Fatal("output goroutine and -exec commands deadlocked")
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	neturl "net/url"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var (
+	// refreshURLRe takes the target out of a refresh content such as
+	// "0; url='/login'".
+	refreshURLRe = regexp.MustCompile(`(?i)^\s*\d*\s*[;,]?\s*url\s*=\s*['"]?([^'"]+)`)
+	// jsRedirectRe matches the usual ways scripts send the browser on.
+	jsRedirectRe = regexp.MustCompile(`(?:window\.|document\.|top\.|self\.)?location(?:\.href)?\s*=\s*["']([^"']+)["']|location\.(?:replace|assign)\(\s*["']([^"']+)["']`)
+)
+
+// softRedirect reports where a page sends the browser through a meta
+// refresh or a script, resolved against pageURL. kind is "meta-refresh" or
+// "js".
+func softRedirect(pageURL string, body []byte) (target, kind string) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return "", ""
+	}
+	doc.Find("meta[http-equiv]").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+		equiv, _ := s.Attr("http-equiv")
+		if !strings.EqualFold(strings.TrimSpace(equiv), "refresh") {
+			return true
+		}
+		content, _ := s.Attr("content")
+		if m := refreshURLRe.FindStringSubmatch(content); m != nil {
+			target, kind = strings.TrimSpace(m[1]), "meta-refresh"
+			return false
+		}
+		return true
+	})
+	if target == "" {
+		doc.Find("script").EachWithBreak(func(_ int, s *goquery.Selection) bool {
+			if m := jsRedirectRe.FindStringSubmatch(s.Text()); m != nil {
+				target, kind = m[1]+m[2], "js"
+				return false
+			}
+			return true
+		})
+	}
+	if target == "" {
+		return "", ""
+	}
+	if base, err := neturl.Parse(pageURL); err == nil {
+		if u, err := base.Parse(target); err == nil {
+			target = u.String()
+		}
+	}
+	return target, kind
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var sortFields = map[string]func(a, b Result) int{
+	"status": func(a, b Result) int { return a.Status - b.Status },
+	"length": func(a, b Result) int { return a.Length - b.Length },
+	"url":    func(a, b Result) int { return strings.Compare(a.URL, b.URL) },
+	"time":   func(a, b Result) int { return int(a.elapsed - b.elapsed) },
+}
+
+// With -sort results are held here until the scan ends instead of being
+// written as they come in.
+var (
+	sortMu     sync.Mutex
+	sorting    bool
+	sortBuffer []Result
+)
+
+// checkSort validates -sort. Sorting needs every result before the first
+// one is written, so it cannot be combined with outputs that act on each
+// result as it arrives.
+func checkSort(field string) error {
+	if _, ok := sortFields[field]; !ok {
+		return fmt.Errorf("%q: use status, length, url or time", field)
+	}
+	switch {
+	case *execCmd != "":
+		return fmt.Errorf("-sort holds results until the scan ends and cannot be combined with -exec, which runs per result as it is found")
+	case *interval > 0:
+		return fmt.Errorf("-sort holds results until the scan ends and cannot be combined with -interval, which reports each pass as it runs")
+	}
+	return nil
+}
+
+// holdForSort buffers r when -sort is on, reporting whether it did.
+func holdForSort(r Result) bool {
+	sortMu.Lock()
+	defer sortMu.Unlock()
+	if !sorting {
+		return false
+	}
+	sortBuffer = append(sortBuffer, r)
+	return true
+}
+
+// flushSorted writes the buffered results in -sort order. Results arriving
+// afterwards, e.g. from requests still in flight, are written directly.
+func flushSorted() {
+	sortMu.Lock()
+	rows := sortBuffer
+	sortBuffer, sorting = nil, false
+	sortMu.Unlock()
+
+	cmp := sortFields[*sortField]
+	sort.SliceStable(rows, func(i, j int) bool {
+		c := cmp(rows[i], rows[j])
+		if *sortDesc {
+			c = -c
+		}
+		if c != 0 {
+			return c < 0
+		}
+		return rows[i].URL < rows[j].URL
+	})
+
+	outputsMu.RLock()
+	defer outputsMu.RUnlock()
+	if outputsClosed {
+		return
+	}
+	for _, r := range rows {
+		emitResult(r)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+)
+
+// statusBarWidth is the length of the bar for the most common status class.
+const statusBarWidth = 30
+
+var (
+	statusClassesMu sync.Mutex
+	// statusClasses counts printed results by status class, 1 to 5 for
+	// 1xx to 5xx.
+	statusClasses [6]int
+)
+
+// countStatus records a printed result's status class for the summary.
+func countStatus(status int) {
+	class := status / 100
+	if class < 1 || class > 5 {
+		return
+	}
+	statusClassesMu.Lock()
+	statusClasses[class]++
+	statusClassesMu.Unlock()
+}
+
+// printStatusBars draws a bar per status class scaled to the most common
+// one. Without color, e.g. when stdout is not a terminal, only the counts
+// are printed.
+func printStatusBars() {
+	statusClassesMu.Lock()
+	counts := statusClasses
+	statusClassesMu.Unlock()
+
+	max := 0
+	for _, n := range counts {
+		if n > max {
+			max = n
+		}
+	}
+	if max == 0 {
+		return
+	}
+	colors := [6]func(...any) string{1: blue, 2: green, 3: blue, 4: yellow, 5: red}
+
+	fmt.Println("Statuses:")
+	for class := 1; class <= 5; class++ {
+		n := counts[class]
+		if n == 0 {
+			continue
+		}
+		if color.NoColor {
+			fmt.Printf("  %dxx %d\n", class, n)
+			continue
+		}
+		width := n * statusBarWidth / max
+		if width == 0 {
+			width = 1
+		}
+		fmt.Printf("  %dxx %s %d\n", class, colors[class](strings.Repeat("█", width)), n)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// setFlag sets a command-line flag for the duration of the test.
+func setFlag(t *testing.T, name, value string) {
+	t.Helper()
+	f := flag.Lookup(name)
+	if f == nil {
+		t.Fatalf("no flag -%s", name)
+	}
+	old := f.Value.String()
+	if err := flag.Set(name, value); err != nil {
+		t.Fatalf("-%s %s: %v", name, value, err)
+	}
+	t.Cleanup(func() { flag.Set(name, old) })
+}
+
+// startOutputs starts the result queue without output files, as
+// openOutputs does for a scan without -o.
+func startOutputs(t *testing.T) {
+	t.Helper()
+	outputs = nil
+	outputsClosed = false
+	resultQueue = startSinkQueue()
+	t.Cleanup(closeOutputs)
+}
+
+// countingServer answers the paths in found with 200 and everything else
+// with 404, counting the requests per path.
+type countingServer struct {
+	*httptest.Server
+	mu   sync.Mutex
+	hits map[string]int
+}
+
+func newCountingServer(t *testing.T, found ...string) *countingServer {
+	t.Helper()
+	s := &countingServer{hits: make(map[string]int)}
+	ok := make(map[string]bool)
+	for _, p := range found {
+		ok[p] = true
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.hits[r.URL.Path]++
+		s.mu.Unlock()
+		if !ok[r.URL.Path] {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("<html><title>found</title></html>"))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *countingServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.hits[path]
+}
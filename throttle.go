@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxRetryAfter    = 10 * time.Minute
+	throttledRetries = 3
+)
+
+// hostPause is the time before which no request may be sent to a host that
+// answered 429/503 with Retry-After.
+type hostPause struct {
+	mu    sync.Mutex
+	until time.Time
+	timer *time.Timer
+}
+
+var pauses sync.Map // host -> *hostPause
+
+func pauseFor(host string) *hostPause {
+	p, _ := pauses.LoadOrStore(host, &hostPause{})
+	return p.(*hostPause)
+}
+
+func parseRetryAfter(v string) time.Duration {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0
+	}
+	var d time.Duration
+	if secs, err := strconv.Atoi(v); err == nil {
+		d = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(v); err == nil {
+		d = time.Until(at)
+	}
+	if d < 0 {
+		return 0
+	}
+	if d > maxRetryAfter {
+		return maxRetryAfter
+	}
+	return d
+}
+
+// pauseHost stops all requests to host for d, extending any pause in progress.
+func pauseHost(host string, d time.Duration) {
+	p := pauseFor(host)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	until := time.Now().Add(d)
+	if !until.After(p.until) {
+		return
+	}
+	if p.timer == nil {
+		emitLine(fmt.Sprintf("%s %s paused for %s (Retry-After)", yellow("[throttle]"), host, d.Round(time.Second)))
+		logger.Info("host paused for Retry-After", "host", host, "delay", d)
+		p.timer = time.AfterFunc(d, func() { resumeHost(host, p) })
+	}
+	p.until = until
+}
+
+func resumeHost(host string, p *hostPause) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if remaining := time.Until(p.until); remaining > 0 {
+		p.timer = time.AfterFunc(remaining, func() { resumeHost(host, p) })
+		return
+	}
+	p.timer = nil
+	emitLine(fmt.Sprintf("%s %s resumed", yellow("[throttle]"), host))
+}
+
+// waitForHost blocks while host is paused. It reports false if ctx ends
+// first, and the caller then skips the request.
+func waitForHost(ctx context.Context, host string) bool {
+	v, ok := pauses.Load(host)
+	if !ok {
+		return true
+	}
+	p := v.(*hostPause)
+	for {
+		p.mu.Lock()
+		remaining := time.Until(p.until)
+		p.mu.Unlock()
+		if remaining <= 0 {
+			return true
+		}
+		select {
+		case <-time.After(remaining):
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// A Retry-After pause ends early when the scan is stopped.
+func TestWaitForHostStops(t *testing.T) {
+	const host = "paused.test"
+	pauseHost(host, maxRetryAfter)
+	t.Cleanup(func() {
+		p := pauseFor(host)
+		p.mu.Lock()
+		p.timer.Stop()
+		p.mu.Unlock()
+		pauses.Delete(host)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if waitForHost(ctx, host) {
+		t.Error("waitForHost reported the host free while it is paused")
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("waitForHost returned after %s, want about 50ms", d)
+	}
+	if !waitForHost(context.Background(), "other.test") {
+		t.Error("waitForHost blocked a host that is not paused")
+	}
+}
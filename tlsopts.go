@@ -0,0 +1,73 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+var tlsVersions = map[string]uint16{
+	"tls1.0": tls.VersionTLS10,
+	"tls1.1": tls.VersionTLS11,
+	"tls1.2": tls.VersionTLS12,
+	"tls1.3": tls.VersionTLS13,
+}
+
+// clientTLS is the TLS configuration every client uses; nil keeps the Go
+// defaults.
+var clientTLS *tls.Config
+
+// parseTLSOptions builds clientTLS from -tls-min, -tls-max, -ciphers and
+// -sni. A ServerName in the config is sent (and the certificate checked
+// against it) for every host instead of the URL host, which is what lets a
+// target be scanned by IP while the handshake names the site.
+func parseTLSOptions(min, max, ciphers, sni string) (*tls.Config, error) {
+	if min == "" && max == "" && ciphers == "" && sni == "" {
+		return nil, nil
+	}
+	cfg := &tls.Config{ServerName: sni}
+	var err error
+	if cfg.MinVersion, err = parseTLSVersion("-tls-min", min); err != nil {
+		return nil, err
+	}
+	if cfg.MaxVersion, err = parseTLSVersion("-tls-max", max); err != nil {
+		return nil, err
+	}
+	if cfg.MinVersion != 0 && cfg.MaxVersion != 0 && cfg.MinVersion > cfg.MaxVersion {
+		return nil, fmt.Errorf("-tls-min %s is above -tls-max %s", min, max)
+	}
+
+	if ciphers != "" {
+		known := make(map[string]*tls.CipherSuite)
+		for _, s := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+			known[s.Name] = s
+		}
+		for _, name := range strings.Split(ciphers, ",") {
+			name = strings.ToUpper(strings.TrimSpace(name))
+			s, ok := known[name]
+			if !ok {
+				return nil, fmt.Errorf("unknown cipher suite %q (use Go names such as TLS_RSA_WITH_AES_128_CBC_SHA)", name)
+			}
+			if len(s.SupportedVersions) == 1 && s.SupportedVersions[0] == tls.VersionTLS13 {
+				return nil, fmt.Errorf("%s is a TLS 1.3 suite; TLS 1.3 suites cannot be selected", name)
+			}
+			cfg.CipherSuites = append(cfg.CipherSuites, s.ID)
+		}
+	}
+	return cfg, nil
+}
+
+func parseTLSVersion(flagName, s string) (uint16, error) {
+	if s == "" {
+		return 0, nil
+	}
+	key := strings.ToLower(s)
+	if !strings.HasPrefix(key, "tls") {
+		key = "tls" + key
+	}
+	v, ok := tlsVersions[key]
+	if !ok {
+		return 0, fmt.Errorf("%s %q: use tls1.0, tls1.1, tls1.2 or tls1.3", flagName, s)
+	}
+	return v, nil
+}
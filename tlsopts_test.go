@@ -0,0 +1,46 @@
+package main
+
+import (
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// -sni names the site in the ClientHello while the connection goes to the
+// URL host, here an IP that would otherwise send no server name at all.
+func TestSNI(t *testing.T) {
+	var mu sync.Mutex
+	var got string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		got = r.TLS.ServerName
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+	t.Cleanup(func() { clientTLS = nil })
+
+	for _, sni := range []string{"example.com", ""} {
+		// -tls-min so there is a config to add the test root to without -sni.
+		cfg, err := parseTLSOptions("tls1.2", "", "", sni)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cfg.RootCAs = roots
+		clientTLS = cfg
+
+		if _, _, meta, err := getStatusCode(newClient(), Target{URL: srv.URL}, srv.URL+"/"); err != nil {
+			t.Fatalf("-sni %q: %v", sni, err)
+		} else {
+			releaseBody(meta.buf)
+		}
+		mu.Lock()
+		if got != sni {
+			t.Errorf("-sni %q: server saw %q", sni, got)
+		}
+		mu.Unlock()
+	}
+}
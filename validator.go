@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// validatorHook runs -validator for every candidate match and only lets it
+// through when the command exits 0, with at most -validator-workers commands
+// at once.
+//
+// As with -exec the command is run directly, not through a shell, with the
+// placeholders filled in per argument. It still runs with the user's
+// privileges on values taken from the target (a URL or title crafted by the
+// server), so it must treat them as untrusted and must not pass them on to a
+// shell unquoted.
+type validatorHook struct {
+	argv     []string
+	timeout  time.Duration
+	sem      chan struct{}
+	rejected atomic.Int64
+
+	logMu sync.Mutex
+	log   *os.File
+}
+
+var validator *validatorHook
+
+func newValidatorHook(template string, workers int, timeout time.Duration, logPath string) (*validatorHook, error) {
+	argv, err := splitArgs(template)
+	if err != nil {
+		return nil, err
+	}
+	if len(argv) == 0 {
+		return nil, errors.New("empty command")
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	v := &validatorHook{argv: argv, timeout: timeout, sem: make(chan struct{}, workers)}
+	if logPath != "" {
+		v.log, err = os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// accept runs the validator for r and reports whether it exited 0. A
+// command that cannot be started or runs past -validator-timeout rejects
+// the match.
+func (v *validatorHook) accept(r Result) bool {
+	replacer := strings.NewReplacer(
+		"{url}", r.URL,
+		"{status}", strconv.Itoa(r.Status),
+		"{length}", strconv.Itoa(r.Length),
+		"{title}", r.Title,
+		"{host}", r.Host,
+	)
+	args := make([]string, len(v.argv))
+	for i, a := range v.argv {
+		args[i] = replacer.Replace(a)
+	}
+
+	v.sem <- struct{}{}
+	defer func() { <-v.sem }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), v.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	switch {
+	case ctx.Err() != nil:
+		emitLine(red("Validator timed out: ") + strings.Join(args, " "))
+	case err != nil && !errors.As(err, &exitErr):
+		emitLine(fmt.Sprint(red("Validator failed: "), strings.Join(args, " "), " ", err))
+	}
+	if v.log != nil && (err != nil || stderr.Len() > 0) {
+		v.logMu.Lock()
+		fmt.Fprintf(v.log, "$ %s\n", strings.Join(args, " "))
+		if err != nil {
+			fmt.Fprintf(v.log, "# %v\n", err)
+		}
+		v.log.Write(stderr.Bytes())
+		v.logMu.Unlock()
+	}
+	if err != nil {
+		v.rejected.Add(1)
+		return false
+	}
+	return true
+}
+
+func (v *validatorHook) Close() {
+	if v.log != nil {
+		v.log.Close()
+	}
+}
+
+func printValidatorSummary() {
+	if n := validator.rejected.Swap(0); n > 0 {
+		fmt.Println(yellow(fmt.Sprintf("[validator] %d matches rejected", n)))
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	neturl "net/url"
+	"strings"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+// variant rewrites a target URL to another scheme, port or both, for
+// -diff-variants.
+type variant struct {
+	scheme string
+	port   string
+}
+
+// variantResponse is the second -diff-variants response for a result's
+// path.
+type variantResponse struct {
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+	Length int    `json:"length"`
+	Title  string `json:"title"`
+}
+
+var diffPair [2]variant
+
+// parseVariants parses -diff-variants: two comma-separated variants, each
+// a scheme, :port or scheme:port.
+func parseVariants(s string) ([2]variant, error) {
+	var out [2]variant
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return out, errors.New("want two variants, e.g. http,https or :8080,:8081")
+	}
+	for i, p := range parts {
+		scheme, port, _ := strings.Cut(strings.TrimSpace(p), ":")
+		if scheme != "" && scheme != "http" && scheme != "https" {
+			return out, fmt.Errorf("%q: scheme must be http or https", p)
+		}
+		if port != "" && !isPort(port) {
+			return out, fmt.Errorf("%q: invalid port", p)
+		}
+		if scheme == "" && port == "" {
+			return out, fmt.Errorf("empty variant in %q", s)
+		}
+		out[i] = variant{scheme: scheme, port: port}
+	}
+	if out[0] == out[1] {
+		return out, errors.New("the two variants are the same")
+	}
+	return out, nil
+}
+
+func isPort(s string) bool {
+	n := 0
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+		n = n*10 + int(c-'0')
+		if n > 65535 {
+			return false
+		}
+	}
+	return n > 0
+}
+
+// apply returns rawURL with the variant's scheme and port.
+func (v variant) apply(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	if v.scheme != "" {
+		u.Scheme = v.scheme
+	}
+	if v.port != "" {
+		u.Host = net.JoinHostPort(u.Hostname(), v.port)
+	}
+	return u.String()
+}
+
+// responsesDiffer reports whether two responses for one path differ enough
+// to report: another status where either is shown, or the same shown
+// status with lengths more than 10% apart or bodies less similar than
+// -similarity-threshold. Two responses that would both be hidden, such as
+// two different 404 pages, never count.
+func responsesDiffer(dir job, aStatus int, a []byte, bStatus int, b []byte) bool {
+	if !jobStatusAllowed(dir, aStatus) && !jobStatusAllowed(dir, bStatus) {
+		return false
+	}
+	if aStatus != bStatus {
+		return true
+	}
+	longer := max(len(a), len(b))
+	if delta := len(a) - len(b); delta*10 > longer || -delta*10 > longer {
+		return true
+	}
+	return similarity(simhash(stripReflection(a, dir.Values...)), simhash(stripReflection(b, dir.Values...))) < *similarityThreshold
+}
+
+// scanVariants requests every path over both -diff-variants of each target
+// and reports the paths whose responses differ, with the second response
+// alongside the first.
+func scanVariants(ctx context.Context, urls []Target, dirs []job) {
+	client := newClient()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, *threads)
+	for _, t := range urls {
+		var pair [2]Target
+		for i, v := range diffPair {
+			pair[i] = t
+			pair[i].URL = v.apply(t.URL)
+		}
+		if pair[0].URL == pair[1].URL {
+			emitLine(yellow("[diff-variants] both variants are the same URL, skipping") + " " + t.URL)
+			continue
+		}
+		for _, dir := range dirs {
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			}
+			wg.Add(1)
+			go func(pair [2]Target, dir job) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+				compareVariants(ctx, client, pair, dir)
+			}(pair, dir)
+		}
+	}
+	wg.Wait()
+}
+
+func compareVariants(ctx context.Context, client *fasthttp.Client, pair [2]Target, dir job) {
+	var status [2]int
+	var body [2][]byte
+	var target [2]string
+	for i, t := range pair {
+		if !takeRequest() {
+			return
+		}
+		target[i] = dir.URL(t.URL)
+		host := hostOf(t.URL)
+		if !waitForHost(ctx, host) {
+			return
+		}
+		var err error
+		status[i], body[i], _, err = getStatusCode(client, t, target[i])
+		if err != nil {
+			recordError(err)
+			logger.Warn("request failed", "host", host, "path", pathOf(target[i]), "error", err)
+			return
+		}
+	}
+	if !responsesDiffer(dir, status[0], body[0], status[1], body[1]) {
+		return
+	}
+	writeResult(Result{
+		URL:    target[0],
+		Host:   hostOf(pair[0].URL),
+		Status: status[0],
+		Length: len(body[0]),
+		Title:  extractTitle(body[0]),
+		Variant: &variantResponse{
+			URL:    target[1],
+			Status: status[1],
+			Length: len(body[1]),
+			Title:  extractTitle(body[1]),
+		},
+	})
+}
+
+// variantString renders both sides of a -diff-variants result as
+// "[200 5120b ≠ 404 162b https://host:8443/admin]".
+func variantString(r Result) string {
+	v := r.Variant
+	return fmt.Sprintf("[%d %db ≠ %d %db %s]", r.Status, r.Length, v.Status, v.Length, v.URL)
+}
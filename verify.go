@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type heldMatch struct {
+	target Target
+	res    Result
+}
+
+// verifyQueue holds the matches of a -verify pass until they have been
+// requested a second time.
+type verifyQueue struct {
+	mu      sync.Mutex
+	matches []heldMatch
+}
+
+var verifier *verifyQueue
+
+func (q *verifyQueue) hold(t Target, r Result) {
+	q.mu.Lock()
+	q.matches = append(q.matches, heldMatch{target: t, res: r})
+	q.mu.Unlock()
+}
+
+// run requests every held match again and writes those that come back with
+// the same status; the others were most likely transient glitches.
+func (q *verifyQueue) run(ctx context.Context, threads int) {
+	q.mu.Lock()
+	matches := q.matches
+	q.matches = nil
+	q.mu.Unlock()
+	if len(matches) == 0 {
+		return
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var dropped []string
+	jobs := make(chan heldMatch)
+	for i := 0; i < threads; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := newClient()
+			for m := range jobs {
+				if !takeRequest() {
+					// -limit is spent; report the match unverified.
+					writeResult(m.res)
+					continue
+				}
+				status, _, _, err := getStatusCode(client, m.target, m.res.URL)
+				if err == nil && status == m.res.Status {
+					writeResult(m.res)
+					continue
+				}
+				mu.Lock()
+				dropped = append(dropped, m.res.URL)
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, m := range matches {
+		if ctx.Err() != nil {
+			// Nothing left to verify against; keep the rest unverified.
+			writeResult(m.res)
+			continue
+		}
+		jobs <- m
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(dropped) > 0 {
+		fmt.Println(yellow(fmt.Sprintf("[verify] %d of %d matches did not reproduce and were dropped", len(dropped), len(matches))))
+		if *verbose {
+			for _, url := range dropped {
+				fmt.Println("    " + url)
+			}
+		}
+	} else {
+		fmt.Println(blue(fmt.Sprintf("[verify] all %d matches reproduced", len(matches))))
+	}
+}
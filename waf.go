@@ -0,0 +1,95 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/valyala/fasthttp"
+)
+
+var (
+	wafMu   sync.Mutex
+	wafRand *rand.Rand
+)
+
+var wafAccepts = []string{
+	"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+	"text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8",
+	"*/*",
+	"text/html;q=0.9,*/*;q=0.5",
+	"application/json, text/plain, */*",
+}
+
+var wafLanguages = []string{
+	"en-US,en;q=0.9",
+	"en-GB,en;q=0.8",
+	"zh-CN,zh;q=0.9,en;q=0.8",
+	"de-DE,de;q=0.9,en;q=0.7",
+	"fr-FR,fr;q=0.9",
+	"ja,en-US;q=0.8",
+}
+
+var wafUserAgents = []string{
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Safari/605.1.15",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0",
+	"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+	"Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+	"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.0.0",
+}
+
+var wafExtraHeaders = [][2]string{
+	{"Cache-Control", "no-cache"},
+	{"Pragma", "no-cache"},
+	{"DNT", "1"},
+	{"Upgrade-Insecure-Requests", "1"},
+	{"Sec-Fetch-Mode", "navigate"},
+	{"Sec-Fetch-Site", "none"},
+	{"Sec-Fetch-Dest", "document"},
+}
+
+// initWAFEvasion seeds the randomizer; a zero seed picks a random one.
+func initWAFEvasion(seed int64) {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	wafRand = rand.New(rand.NewSource(seed))
+}
+
+// applyWAFEvasion varies the request's headers so consecutive requests do
+// not share a fixed signature. Intended for authorized testing only.
+func applyWAFEvasion(req *fasthttp.Request) {
+	wafMu.Lock()
+	defer wafMu.Unlock()
+
+	req.Header.DisableNormalizing()
+	req.Header.Set(randomCase("Accept"), wafAccepts[wafRand.Intn(len(wafAccepts))])
+	req.Header.Set(randomCase("Accept-Language"), wafLanguages[wafRand.Intn(len(wafLanguages))])
+
+	for _, i := range wafRand.Perm(len(wafExtraHeaders))[:1+wafRand.Intn(3)] {
+		h := wafExtraHeaders[i]
+		req.Header.Set(randomCase(h[0]), h[1])
+	}
+}
+
+// applyRandomAgent sets one of wafUserAgents as the User-Agent.
+func applyRandomAgent(req *fasthttp.Request) {
+	wafMu.Lock()
+	defer wafMu.Unlock()
+	req.Header.SetUserAgent(wafUserAgents[wafRand.Intn(len(wafUserAgents))])
+}
+
+func randomCase(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if wafRand.Intn(2) == 0 {
+			b.WriteRune(unicode.ToUpper(r))
+		} else {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
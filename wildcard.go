@@ -0,0 +1,63 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// wildcardPage is the response a target gives for a path that should not
+// exist, used to recognise soft-404 pages.
+type wildcardPage struct {
+	Status int
+	Length int
+	Hash   uint64
+}
+
+var wildcards sync.Map // base URL -> *wildcardPage
+
+func randomPath() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func detectWildcards(urls []Target) {
+	client := newClient()
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, *threads)
+	for _, t := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(t Target) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if !takeRequest() {
+				return
+			}
+			probe := randomPath()
+			statusCode, body, _, err := getStatusCode(client, t, probeURL(t.URL, probe))
+			if err != nil || statusCode == 404 {
+				logger.Debug("no soft-404 page", "target", t.URL, "status", statusCode, "error", err)
+				return
+			}
+			logger.Info("soft-404 page detected", "target", t.URL, "status", statusCode, "length", len(body))
+			wildcards.Store(t.URL, &wildcardPage{
+				Status: statusCode,
+				Length: len(body),
+				Hash:   simhash(stripReflection(body, probe)),
+			})
+		}(t)
+	}
+	wg.Wait()
+}
+
+func getWildcard(baseURL string) *wildcardPage {
+	if w, ok := wildcards.Load(baseURL); ok {
+		return w.(*wildcardPage)
+	}
+	return nil
+}
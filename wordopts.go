@@ -0,0 +1,59 @@
+package main
+
+import "strings"
+
+// Wordlist lines may end in options, each a space and "!name":
+//
+//	admin !nofollow
+//
+// The only option so far is !nofollow, which keeps -recursion from
+// descending into what the word finds. Anything else after a '!' stays part
+// of the word, so lists without options read as before.
+
+// noFollowWords are the words marked !nofollow, as written in the list.
+var noFollowWords = make(map[string]bool)
+
+// wordOptions splits the options off a wordlist line and records them for
+// the word.
+func wordOptions(line string) string {
+	noFollow := false
+	for {
+		i := strings.LastIndexAny(line, " \t")
+		if i < 0 || strings.TrimSpace(line[i+1:]) != "!nofollow" {
+			break
+		}
+		line = strings.TrimSpace(line[:i])
+		noFollow = true
+	}
+	if noFollow {
+		noFollowWords[line] = true
+	}
+	return line
+}
+
+// isWordlistComment reports whether a wordlist line is a "#" comment. When
+// -stop-on-first sections are in effect such lines start sections instead
+// and are kept.
+func isWordlistComment(line string, sections bool) bool {
+	return strings.HasPrefix(line, "#") && !sections
+}
+
+// noFollow reports whether one of the job's words was marked !nofollow,
+// looking through -prefix, -suffix and a -x extension.
+func (j job) noFollow() bool {
+	if len(noFollowWords) == 0 {
+		return false
+	}
+	for i, v := range j.Values {
+		w := strings.TrimSuffix(strings.TrimPrefix(v, *prefix), *suffix)
+		if noFollowWords[w] {
+			return true
+		}
+		if i == len(j.Values)-1 {
+			if ext := j.ext(); ext != "" && noFollowWords[strings.TrimSuffix(w, "."+ext)] {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// A worker that panics is replaced: the scan still finishes and all
+// -t workers are busy at once afterwards.
+func TestWorkerPanicKeepsPool(t *testing.T) {
+	const threads = 4
+	var inFlight, most atomic.Int32
+	fetchResponse = func(_ *fasthttp.Client, _ Target, dir job, _, _ string) (int, []byte, responseMeta, error) {
+		word := dir.Values[0]
+		if strings.HasPrefix(word, "boom") {
+			panic("injected")
+		}
+		if strings.HasPrefix(word, "wait") {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+			}
+			// Hold until the whole pool is in here, or give up.
+			deadline := time.Now().Add(2 * time.Second)
+			for most.Load() < threads && time.Now().Before(deadline) {
+				time.Sleep(time.Millisecond)
+			}
+		}
+		return 404, nil, responseMeta{}, nil
+	}
+	t.Cleanup(func() { fetchResponse = fetch })
+
+	var dirs []job
+	for _, w := range []string{"boom1", "boom2", "boom3", "boom4", "boom5", "wait1", "wait2", "wait3", "wait4", "after"} {
+		dirs = append(dirs, job{Values: []string{w}})
+	}
+	done := make(chan struct{})
+	go func() {
+		runScan(context.Background(), dirs, []Target{{URL: "http://panic.test/"}}, threads)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("scan did not finish after worker panics")
+	}
+	if n := most.Load(); n != threads {
+		t.Errorf("at most %d workers ran at once after the panics, want %d", n, threads)
+	}
+}
+
+// A request that finds the connection pool full is retried until a
+// connection frees up instead of being dropped as a miss.
+func TestPoolExhaustionRetried(t *testing.T) {
+	var calls atomic.Int32
+	fetchResponse = func(_ *fasthttp.Client, _ Target, _ job, _, _ string) (int, []byte, responseMeta, error) {
+		if calls.Add(1) <= 2 {
+			return 0, nil, responseMeta{}, fasthttp.ErrNoFreeConns
+		}
+		return 200, []byte("<title>found</title>"), responseMeta{}, nil
+	}
+	t.Cleanup(func() { fetchResponse = fetch })
+	setFlag(t, "retries", "0")
+	startOutputs(t)
+
+	before := matchCount.Load()
+	runScan(context.Background(), []job{{Values: []string{"admin"}}}, []Target{{URL: "http://pool.test/"}}, 1)
+	flushOutputs()
+	if n := calls.Load(); n != 3 {
+		t.Errorf("fetched %d times, want 3", n)
+	}
+	if n := matchCount.Load() - before; n != 1 {
+		t.Errorf("got %d results, want 1", n)
+	}
+}